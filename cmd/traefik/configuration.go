@@ -50,6 +50,18 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 	var defaultFile file.Provider
 	defaultFile.Watch = true
 	defaultFile.Filename = "" //needs equivalent to  viper.ConfigFileUsed()
+	defaultFile.DebounceDuration = flaeg.Duration(500 * time.Millisecond)
+	defaultFile.ReloadRetries = 1
+	defaultFile.ReloadRetryDelay = flaeg.Duration(200 * time.Millisecond)
+	defaultFile.AllowEmptyConfiguration = true
+	defaultFile.ProviderName = "file"
+	defaultFile.CertExpiryWarning = flaeg.Duration(30 * 24 * time.Hour)
+	defaultFile.WatcherRestartMinDelay = flaeg.Duration(500 * time.Millisecond)
+	defaultFile.WatcherRestartMaxDelay = flaeg.Duration(time.Minute)
+	defaultFile.RemotePollInterval = flaeg.Duration(30 * time.Second)
+	defaultFile.PollInterval = flaeg.Duration(5 * time.Second)
+	defaultFile.LenientDecode = true
+	defaultFile.MaxFileSize = 10 * 1024 * 1024
 
 	// default Rest
 	var defaultRest rest.Provider
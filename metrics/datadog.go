@@ -22,6 +22,10 @@ const (
 	ddMetricsReqsName    = "requests.total"
 	ddMetricsLatencyName = "request.duration"
 	ddRetriesTotalName   = "backend.retries.total"
+
+	ddConfigReloadsName        = "config.reloads.total"
+	ddConfigStaleReloadsName   = "config.stale.reloads"
+	ddConfigReloadDurationName = "config.reload.duration"
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -31,10 +35,13 @@ func RegisterDatadog(config *types.Datadog) Registry {
 	}
 
 	registry := &standardRegistry{
-		enabled:              true,
-		reqsCounter:          datadogClient.NewCounter(ddMetricsReqsName, 1.0),
-		reqDurationHistogram: datadogClient.NewHistogram(ddMetricsLatencyName, 1.0),
-		retriesCounter:       datadogClient.NewCounter(ddRetriesTotalName, 1.0),
+		enabled:                       true,
+		reqsCounter:                   datadogClient.NewCounter(ddMetricsReqsName, 1.0),
+		reqDurationHistogram:          datadogClient.NewHistogram(ddMetricsLatencyName, 1.0),
+		retriesCounter:                datadogClient.NewCounter(ddRetriesTotalName, 1.0),
+		configReloadsCounter:          datadogClient.NewCounter(ddConfigReloadsName, 1.0),
+		configStaleReloadsGauge:       datadogClient.NewGauge(ddConfigStaleReloadsName),
+		configReloadDurationHistogram: datadogClient.NewHistogram(ddConfigReloadDurationName, 1.0),
 	}
 
 	return registry
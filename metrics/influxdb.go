@@ -28,6 +28,10 @@ const (
 	influxDBMetricsReqsName    = "traefik.requests.total"
 	influxDBMetricsLatencyName = "traefik.request.duration"
 	influxDBRetriesTotalName   = "traefik.backend.retries.total"
+
+	influxDBConfigReloadsName        = "traefik.config.reloads.total"
+	influxDBConfigStaleReloadsName   = "traefik.config.stale.reloads"
+	influxDBConfigReloadDurationName = "traefik.config.reload.duration"
 )
 
 // RegisterInfluxDB registers the metrics pusher if this didn't happen yet and creates a InfluxDB Registry instance.
@@ -37,10 +41,13 @@ func RegisterInfluxDB(config *types.InfluxDB) Registry {
 	}
 
 	return &standardRegistry{
-		enabled:              true,
-		reqsCounter:          influxDBClient.NewCounter(influxDBMetricsReqsName),
-		reqDurationHistogram: influxDBClient.NewHistogram(influxDBMetricsLatencyName),
-		retriesCounter:       influxDBClient.NewCounter(influxDBRetriesTotalName),
+		enabled:                       true,
+		reqsCounter:                   influxDBClient.NewCounter(influxDBMetricsReqsName),
+		reqDurationHistogram:          influxDBClient.NewHistogram(influxDBMetricsLatencyName),
+		retriesCounter:                influxDBClient.NewCounter(influxDBRetriesTotalName),
+		configReloadsCounter:          influxDBClient.NewCounter(influxDBConfigReloadsName),
+		configStaleReloadsGauge:       influxDBClient.NewGauge(influxDBConfigStaleReloadsName),
+		configReloadDurationHistogram: influxDBClient.NewHistogram(influxDBConfigReloadDurationName),
 	}
 }
 
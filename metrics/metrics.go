@@ -12,6 +12,9 @@ type Registry interface {
 	ReqsCounter() metrics.Counter
 	ReqDurationHistogram() metrics.Histogram
 	RetriesCounter() metrics.Counter
+	ConfigReloadsCounter() metrics.Counter
+	ConfigStaleReloadsGauge() metrics.Gauge
+	ConfigReloadDurationHistogram() metrics.Histogram
 }
 
 // NewMultiRegistry creates a new standardRegistry that wraps multiple Registries.
@@ -19,26 +22,38 @@ func NewMultiRegistry(registries []Registry) Registry {
 	reqsCounters := []metrics.Counter{}
 	reqDurationHistograms := []metrics.Histogram{}
 	retriesCounters := []metrics.Counter{}
+	configReloadsCounters := []metrics.Counter{}
+	configStaleReloadsGauges := []metrics.Gauge{}
+	configReloadDurationHistograms := []metrics.Histogram{}
 
 	for _, r := range registries {
 		reqsCounters = append(reqsCounters, r.ReqsCounter())
 		reqDurationHistograms = append(reqDurationHistograms, r.ReqDurationHistogram())
 		retriesCounters = append(retriesCounters, r.RetriesCounter())
+		configReloadsCounters = append(configReloadsCounters, r.ConfigReloadsCounter())
+		configStaleReloadsGauges = append(configStaleReloadsGauges, r.ConfigStaleReloadsGauge())
+		configReloadDurationHistograms = append(configReloadDurationHistograms, r.ConfigReloadDurationHistogram())
 	}
 
 	return &standardRegistry{
-		enabled:              true,
-		reqsCounter:          multi.NewCounter(reqsCounters...),
-		reqDurationHistogram: multi.NewHistogram(reqDurationHistograms...),
-		retriesCounter:       multi.NewCounter(retriesCounters...),
+		enabled:                       true,
+		reqsCounter:                   multi.NewCounter(reqsCounters...),
+		reqDurationHistogram:          multi.NewHistogram(reqDurationHistograms...),
+		retriesCounter:                multi.NewCounter(retriesCounters...),
+		configReloadsCounter:          multi.NewCounter(configReloadsCounters...),
+		configStaleReloadsGauge:       multi.NewGauge(configStaleReloadsGauges...),
+		configReloadDurationHistogram: multi.NewHistogram(configReloadDurationHistograms...),
 	}
 }
 
 type standardRegistry struct {
-	enabled              bool
-	reqsCounter          metrics.Counter
-	reqDurationHistogram metrics.Histogram
-	retriesCounter       metrics.Counter
+	enabled                       bool
+	reqsCounter                   metrics.Counter
+	reqDurationHistogram          metrics.Histogram
+	retriesCounter                metrics.Counter
+	configReloadsCounter          metrics.Counter
+	configStaleReloadsGauge       metrics.Gauge
+	configReloadDurationHistogram metrics.Histogram
 }
 
 func (r *standardRegistry) IsEnabled() bool {
@@ -57,14 +72,29 @@ func (r *standardRegistry) RetriesCounter() metrics.Counter {
 	return r.retriesCounter
 }
 
+func (r *standardRegistry) ConfigReloadsCounter() metrics.Counter {
+	return r.configReloadsCounter
+}
+
+func (r *standardRegistry) ConfigStaleReloadsGauge() metrics.Gauge {
+	return r.configStaleReloadsGauge
+}
+
+func (r *standardRegistry) ConfigReloadDurationHistogram() metrics.Histogram {
+	return r.configReloadDurationHistogram
+}
+
 // NewVoidRegistry is a noop implementation of metrics.Registry.
 // It is used to avoid nil checking in components that do metric collections.
 func NewVoidRegistry() Registry {
 	return &standardRegistry{
-		enabled:              false,
-		reqsCounter:          &voidCounter{},
-		reqDurationHistogram: &voidHistogram{},
-		retriesCounter:       &voidCounter{},
+		enabled:                       false,
+		reqsCounter:                   &voidCounter{},
+		reqDurationHistogram:          &voidHistogram{},
+		retriesCounter:                &voidCounter{},
+		configReloadsCounter:          &voidCounter{},
+		configStaleReloadsGauge:       &voidGauge{},
+		configReloadDurationHistogram: &voidHistogram{},
 	}
 }
 
@@ -73,6 +103,11 @@ type voidCounter struct{}
 func (v *voidCounter) With(labelValues ...string) metrics.Counter { return v }
 func (v *voidCounter) Add(delta float64)                          {}
 
+type voidGauge struct{}
+
+func (g *voidGauge) With(labelValues ...string) metrics.Gauge { return g }
+func (g *voidGauge) Set(value float64)                        {}
+
 type voidHistogram struct{}
 
 func (h *voidHistogram) With(labelValues ...string) metrics.Histogram { return h }
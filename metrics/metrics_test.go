@@ -16,6 +16,9 @@ func TestNewVoidRegistry(t *testing.T) {
 	registry.ReqsCounter().With("some", "value").Add(1)
 	registry.ReqDurationHistogram().With("some", "value").Observe(1)
 	registry.RetriesCounter().With("some", "value").Add(1)
+	registry.ConfigReloadsCounter().With("some", "value").Add(1)
+	registry.ConfigStaleReloadsGauge().With("some", "value").Set(1)
+	registry.ConfigReloadDurationHistogram().With("some", "value").Observe(1)
 }
 
 func TestNewMultiRegistry(t *testing.T) {
@@ -25,11 +28,17 @@ func TestNewMultiRegistry(t *testing.T) {
 	registry.ReqsCounter().With("key", "requests").Add(1)
 	registry.ReqDurationHistogram().With("key", "durations").Observe(2)
 	registry.RetriesCounter().With("key", "retries").Add(3)
+	registry.ConfigReloadsCounter().With("key", "reloads").Add(4)
+	registry.ConfigStaleReloadsGauge().With("key", "stale").Set(5)
+	registry.ConfigReloadDurationHistogram().With("key", "reload").Observe(6)
 
 	for _, collectingRegistry := range registries {
 		cReqsCounter := collectingRegistry.ReqsCounter().(*counterMock)
 		cReqDurationHistogram := collectingRegistry.ReqDurationHistogram().(*histogramMock)
 		cRetriesCounter := collectingRegistry.RetriesCounter().(*counterMock)
+		cConfigReloadsCounter := collectingRegistry.ConfigReloadsCounter().(*counterMock)
+		cConfigStaleReloadsGauge := collectingRegistry.ConfigStaleReloadsGauge().(*gaugeMock)
+		cConfigReloadDurationHistogram := collectingRegistry.ConfigReloadDurationHistogram().(*histogramMock)
 
 		wantCounterValue := float64(1)
 		if cReqsCounter.counterValue != wantCounterValue {
@@ -43,18 +52,36 @@ func TestNewMultiRegistry(t *testing.T) {
 		if cRetriesCounter.counterValue != wantCounterValue {
 			t.Errorf("Got value %f for RetriesCounter, want %f", cRetriesCounter.counterValue, wantCounterValue)
 		}
+		wantCounterValue = float64(4)
+		if cConfigReloadsCounter.counterValue != wantCounterValue {
+			t.Errorf("Got value %f for ConfigReloadsCounter, want %f", cConfigReloadsCounter.counterValue, wantCounterValue)
+		}
+		wantGaugeValue := float64(5)
+		if cConfigStaleReloadsGauge.gaugeValue != wantGaugeValue {
+			t.Errorf("Got value %f for ConfigStaleReloadsGauge, want %f", cConfigStaleReloadsGauge.gaugeValue, wantGaugeValue)
+		}
+		wantHistogramValue = float64(6)
+		if cConfigReloadDurationHistogram.lastHistogramValue != wantHistogramValue {
+			t.Errorf("Got last observation %f for ConfigReloadDurationHistogram, want %f", cConfigReloadDurationHistogram.lastHistogramValue, wantHistogramValue)
+		}
 
 		assert.Equal(t, []string{"key", "requests"}, cReqsCounter.lastLabelValues)
 		assert.Equal(t, []string{"key", "durations"}, cReqDurationHistogram.lastLabelValues)
 		assert.Equal(t, []string{"key", "retries"}, cRetriesCounter.lastLabelValues)
+		assert.Equal(t, []string{"key", "reloads"}, cConfigReloadsCounter.lastLabelValues)
+		assert.Equal(t, []string{"key", "stale"}, cConfigStaleReloadsGauge.lastLabelValues)
+		assert.Equal(t, []string{"key", "reload"}, cConfigReloadDurationHistogram.lastLabelValues)
 	}
 }
 
 func newCollectingRetryMetrics() Registry {
 	return &standardRegistry{
-		reqsCounter:          &counterMock{},
-		reqDurationHistogram: &histogramMock{},
-		retriesCounter:       &counterMock{},
+		reqsCounter:                   &counterMock{},
+		reqDurationHistogram:          &histogramMock{},
+		retriesCounter:                &counterMock{},
+		configReloadsCounter:          &counterMock{},
+		configStaleReloadsGauge:       &gaugeMock{},
+		configReloadDurationHistogram: &histogramMock{},
 	}
 }
 
@@ -72,6 +99,20 @@ func (c *counterMock) Add(delta float64) {
 	c.counterValue += delta
 }
 
+type gaugeMock struct {
+	gaugeValue      float64
+	lastLabelValues []string
+}
+
+func (g *gaugeMock) With(labelValues ...string) metrics.Gauge {
+	g.lastLabelValues = labelValues
+	return g
+}
+
+func (g *gaugeMock) Set(value float64) {
+	g.gaugeValue = value
+}
+
 type histogramMock struct {
 	lastHistogramValue float64
 	lastLabelValues    []string
@@ -16,6 +16,10 @@ const (
 	reqsTotalName    = metricNamePrefix + "requests_total"
 	reqDurationName  = metricNamePrefix + "request_duration_seconds"
 	retriesTotalName = metricNamePrefix + "backend_retries_total"
+
+	configReloadsTotalName   = metricNamePrefix + "config_reloads_total"
+	configStaleReloadsName   = metricNamePrefix + "config_stale_reloads"
+	configReloadDurationName = metricNamePrefix + "config_reload_duration_seconds"
 )
 
 // PrometheusHandler expose Prometheus routes
@@ -47,11 +51,27 @@ func RegisterPrometheus(config *types.Prometheus) Registry {
 		Name: retriesTotalName,
 		Help: "How many request retries happened in total.",
 	}, []string{"service"})
+	configReloadsCounter := prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Name: configReloadsTotalName,
+		Help: "How many configurations have been sent by the providers, partitioned by provider and trigger.",
+	}, []string{"provider", "trigger"})
+	configStaleReloadsGauge := prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Name: configStaleReloadsName,
+		Help: "How many configuration reloads failed since the last configuration sent, partitioned by provider.",
+	}, []string{"provider"})
+	configReloadDurationHistogram := prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Name:    configReloadDurationName,
+		Help:    "How long it took to load a configuration, partitioned by provider and trigger.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1.0, 5.0},
+	}, []string{"provider", "trigger"})
 
 	return &standardRegistry{
-		enabled:              true,
-		reqsCounter:          reqCounter,
-		reqDurationHistogram: reqDurationHistogram,
-		retriesCounter:       retryCounter,
+		enabled:                       true,
+		reqsCounter:                   reqCounter,
+		reqDurationHistogram:          reqDurationHistogram,
+		retriesCounter:                retryCounter,
+		configReloadsCounter:          configReloadsCounter,
+		configStaleReloadsGauge:       configStaleReloadsGauge,
+		configReloadDurationHistogram: configReloadDurationHistogram,
 	}
 }
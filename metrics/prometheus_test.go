@@ -21,6 +21,9 @@ func TestPrometheus(t *testing.T) {
 	prometheusRegistry.ReqDurationHistogram().With("service", "test", "code", strconv.Itoa(http.StatusOK)).Observe(10000)
 	prometheusRegistry.ReqDurationHistogram().With("service", "test", "code", strconv.Itoa(http.StatusOK)).Observe(10000)
 	prometheusRegistry.RetriesCounter().With("service", "test").Add(1)
+	prometheusRegistry.ConfigReloadsCounter().With("provider", "file", "trigger", "watch").Add(1)
+	prometheusRegistry.ConfigStaleReloadsGauge().With("provider", "file").Set(2)
+	prometheusRegistry.ConfigReloadDurationHistogram().With("provider", "file", "trigger", "initial").Observe(0.2)
 
 	metricsFamilies, err := prometheus.DefaultGatherer.Gather()
 	if err != nil {
@@ -74,6 +77,47 @@ func TestPrometheus(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: configReloadsTotalName,
+			labels: map[string]string{
+				"provider": "file",
+				"trigger":  "watch",
+			},
+			assert: func(family *dto.MetricFamily) {
+				cv := family.Metric[0].Counter.GetValue()
+				expectedCv := float64(1)
+				if cv != expectedCv {
+					t.Errorf("gathered metrics do not contain correct value for total config reloads, got %f expected %f", cv, expectedCv)
+				}
+			},
+		},
+		{
+			name: configStaleReloadsName,
+			labels: map[string]string{
+				"provider": "file",
+			},
+			assert: func(family *dto.MetricFamily) {
+				gv := family.Metric[0].Gauge.GetValue()
+				expectedGv := float64(2)
+				if gv != expectedGv {
+					t.Errorf("gathered metrics do not contain correct value for stale config reloads, got %f expected %f", gv, expectedGv)
+				}
+			},
+		},
+		{
+			name: configReloadDurationName,
+			labels: map[string]string{
+				"provider": "file",
+				"trigger":  "initial",
+			},
+			assert: func(family *dto.MetricFamily) {
+				sc := family.Metric[0].Histogram.GetSampleCount()
+				expectedSc := uint64(1)
+				if sc != expectedSc {
+					t.Errorf("gathered metrics do not contain correct sample count for config reload duration, got %d expected %d", sc, expectedSc)
+				}
+			},
+		},
 	}
 
 	for _, test := range tests {
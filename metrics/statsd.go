@@ -21,6 +21,10 @@ const (
 	statsdMetricsReqsName    = "requests.total"
 	statsdMetricsLatencyName = "request.duration"
 	statsdRetriesTotalName   = "backend.retries.total"
+
+	statsdConfigReloadsName        = "config.reloads.total"
+	statsdConfigStaleReloadsName   = "config.stale.reloads"
+	statsdConfigReloadDurationName = "config.reload.duration"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -30,10 +34,13 @@ func RegisterStatsd(config *types.Statsd) Registry {
 	}
 
 	return &standardRegistry{
-		enabled:              true,
-		reqsCounter:          statsdClient.NewCounter(statsdMetricsReqsName, 1.0),
-		reqDurationHistogram: statsdClient.NewTiming(statsdMetricsLatencyName, 1.0),
-		retriesCounter:       statsdClient.NewCounter(statsdRetriesTotalName, 1.0),
+		enabled:                       true,
+		reqsCounter:                   statsdClient.NewCounter(statsdMetricsReqsName, 1.0),
+		reqDurationHistogram:          statsdClient.NewTiming(statsdMetricsLatencyName, 1.0),
+		retriesCounter:                statsdClient.NewCounter(statsdRetriesTotalName, 1.0),
+		configReloadsCounter:          statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
+		configStaleReloadsGauge:       statsdClient.NewGauge(statsdConfigStaleReloadsName),
+		configReloadDurationHistogram: statsdClient.NewTiming(statsdConfigReloadDurationName, 1.0),
 	}
 }
 
@@ -0,0 +1,201 @@
+package file
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/log"
+)
+
+// readsArchive returns true if the configuration is loaded from the Archive,
+// the directories and the list of Files taking precedence.
+func (p *Provider) readsArchive() bool {
+	return !p.hasDirectories() && !p.hasFiles() && p.Archive != ""
+}
+
+// isArchive returns true if the path is the Archive.
+func (p *Provider) isArchive(name string) bool {
+	return p.Archive != "" && filepath.Clean(name) == filepath.Clean(p.Archive)
+}
+
+// isGzipArchive returns true for the gzip-compressed tar archives, ending with .gz or .tgz.
+func isGzipArchive(filename string) bool {
+	return isGzipFile(filename) || strings.EqualFold(filepath.Ext(filename), ".tgz")
+}
+
+// archiveMember is a configuration file of the Archive.
+type archiveMember struct {
+	// name is the path of the member in the archive, with slashes.
+	name    string
+	content []byte
+}
+
+// loadArchive loads the configuration files of the Archive into a new cache,
+// in the order of a directory holding the content of the archive.
+// Each member is named by its path in the archive appended to the path of the archive, such as rules.tar/backends.toml.
+func (p *Provider) loadArchive() (*fileCache, error) {
+	if _, err := p.mergeStrategy(); err != nil {
+		return nil, err
+	}
+
+	members, err := p.readArchive()
+	if err != nil {
+		return nil, fmt.Errorf("error reading archive %s: %v", p.Archive, err)
+	}
+
+	fileInfo, err := os.Stat(p.Archive)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := newFileCache()
+	for _, member := range members {
+		filename := filepath.Join(p.Archive, filepath.FromSlash(member.name))
+		entry, err := p.loadArchiveMember(filename, member.content)
+		if err != nil {
+			if !p.SkipInvalidFiles {
+				return nil, err
+			}
+			logSkippedFile(err)
+			continue
+		}
+		entry.modTime = fileInfo.ModTime()
+		cache.add(filename, entry)
+	}
+	return cache, nil
+}
+
+// readArchive returns the configuration files of the Archive, in load order.
+// Like in a directory, the hidden files are skipped unless IncludeHiddenFiles is enabled,
+// as well as the files not matching the FilePattern and the files without one of the extensions to load.
+func (p *Provider) readArchive() ([]archiveMember, error) {
+	file, err := os.Open(p.Archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if isGzipArchive(p.Archive) {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	var members []archiveMember
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(path.Clean(header.Name), "/")
+		if !header.FileInfo().Mode().IsRegular() || !p.isSelectedMember(name) {
+			continue
+		}
+		if p.MaxFileSize > 0 && header.Size > p.MaxFileSize {
+			skipTooLargeFile(&fileTooLargeError{filename: filepath.Join(p.Archive, filepath.FromSlash(name)), maxSize: p.MaxFileSize})
+			continue
+		}
+
+		content, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, archiveMember{name: name, content: content})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return lessArchiveMember(members[i].name, members[j].name)
+	})
+	return members, nil
+}
+
+// isSelectedMember returns true if the member of the archive has to be loaded.
+func (p *Provider) isSelectedMember(name string) bool {
+	if !p.hasSelectedExtension(name) || isPartialTemplate(path.Base(name)) {
+		return false
+	}
+	for _, element := range strings.Split(name, "/") {
+		if !p.IncludeHiddenFiles && isHiddenFile(element) {
+			return false
+		}
+	}
+	if p.FilePattern == "" {
+		return true
+	}
+	matched, err := filepath.Match(p.FilePattern, path.Base(name))
+	return err == nil && matched
+}
+
+// lessArchiveMember orders the members of an archive like the files of a directory:
+// the files of a directory in lexical order, then its sub-directories in lexical order.
+func lessArchiveMember(first, second string) bool {
+	firstElements := strings.Split(first, "/")
+	secondElements := strings.Split(second, "/")
+	for i := 0; ; i++ {
+		firstIsFile := i == len(firstElements)-1
+		secondIsFile := i == len(secondElements)-1
+		if firstIsFile != secondIsFile {
+			return firstIsFile
+		}
+		if firstIsFile || firstElements[i] != secondElements[i] {
+			return firstElements[i] < secondElements[i]
+		}
+	}
+}
+
+// loadArchiveMember loads the configuration file of the archive.
+// Its relative certificate paths are resolved from the directory of the archive, and it can not include other files.
+func (p *Provider) loadArchiveMember(filename string, content []byte) (*cachedFile, error) {
+	content, err := p.decrypt(filename, content)
+	if err != nil {
+		return nil, fmt.Errorf("error reading configuration file %s: %v", filename, err)
+	}
+	if err := checkTextContent(filename, content); err != nil {
+		return nil, err
+	}
+
+	fc, err := p.decodeContent(filename, content)
+	if err != nil {
+		return nil, fmt.Errorf("error reading configuration file %s: %v", filename, err)
+	}
+	if len(fc.Include.Files) > 0 {
+		return nil, fmt.Errorf("error reading configuration file %s: the files of an archive can not include other files", filename)
+	}
+
+	warns := p.newWarnings()
+	configuration := &fc.Configuration
+	if fc.Disabled {
+		log.Infof("Skipping disabled configuration file %s", filename)
+		configuration = disabledConfiguration()
+	} else {
+		warnDuplicateServers(filename, configuration, warns)
+		resolveCertificatePaths(p.Archive, configuration)
+		if err := p.checkKeyPairs(filename, configuration); err != nil {
+			return nil, err
+		}
+	}
+
+	return &cachedFile{
+		configuration: configuration,
+		dependencies:  map[string]struct{}{filepath.Clean(p.Archive): {}},
+		origins:       newConfigurationOrigins(filename, configuration),
+		warnings:      warns,
+	}, nil
+}
@@ -0,0 +1,176 @@
+package file
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConfigurationArchive(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		archive string
+	}{
+		{
+			desc:    "tar archive",
+			archive: "rules.tar",
+		},
+		{
+			desc:    "gzip-compressed tar archive",
+			archive: "rules.tar.gz",
+		},
+		{
+			desc:    "tgz archive",
+			archive: "rules.tgz",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			archive := filepath.Join(tempDir, test.archive)
+			createArchive(t, archive, []archiveMember{
+				{name: "backends.toml", content: []byte(createBackendConfiguration(2))},
+				{name: "sub/"},
+				{name: "sub/frontends.toml", content: []byte(createFrontendConfiguration(2))},
+				{name: ".hidden.toml", content: []byte(createBackendConfiguration(5))},
+				{name: "README.md", content: []byte("# Rules\n")},
+			})
+
+			pvd := &Provider{Archive: archive}
+			configuration, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			assert.Len(t, configuration.Backends, 2)
+			assert.Len(t, configuration.Frontends, 2)
+			assert.Equal(t, []string{archive}, pvd.LoadedFiles())
+			assert.Equal(t, filepath.Join(archive, "sub", "frontends.toml"), pvd.FrontendFile("frontend1"))
+			assert.Equal(t, fmt.Sprintf("archive %q", archive), pvd.configurationSource())
+		})
+	}
+}
+
+func TestBuildConfigurationArchiveErrors(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		members       []archiveMember
+		expectedError string
+	}{
+		{
+			desc:          "invalid member",
+			members:       []archiveMember{{name: "backends.toml", content: []byte("[backends\n")}},
+			expectedError: "backends.toml",
+		},
+		{
+			desc:          "include",
+			members:       []archiveMember{{name: "rules.toml", content: []byte("[include]\nfiles = [\"common.toml\"]\n")}},
+			expectedError: "the files of an archive can not include other files",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			archive := filepath.Join(tempDir, "rules.tar")
+			createArchive(t, archive, test.members)
+
+			pvd := &Provider{Archive: archive}
+			_, err := pvd.BuildConfiguration()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedError)
+		})
+	}
+}
+
+func TestLessArchiveMember(t *testing.T) {
+	names := []string{"b/a.toml", "c.toml", "a/z.toml", "a.toml", "a/b/c.toml", "a/y.toml"}
+	sort.Slice(names, func(i, j int) bool {
+		return lessArchiveMember(names[i], names[j])
+	})
+
+	assert.Equal(t, []string{"a.toml", "c.toml", "a/y.toml", "a/z.toml", "a/b/c.toml", "b/a.toml"}, names)
+}
+
+func TestProvideArchiveAndWatch(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	archive := filepath.Join(tempDir, "rules.tar.gz")
+	createArchive(t, archive, []archiveMember{{name: "backends.toml", content: []byte(createBackendConfiguration(2))}})
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{
+		Archive:          archive,
+		ProviderName:     "file",
+		DebounceDuration: flaeg.Duration(50 * time.Millisecond),
+	}
+	pvd.Watch = true
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	require.NoError(t, pvd.Provide(configurationChan, pool, nil))
+	assert.Len(t, receiveConfiguration(t, configurationChan).Backends, 2)
+
+	// The new version of the archive replaces the previous one atomically
+	newArchive := filepath.Join(tempDir, "rules.new.tar.gz")
+	createArchive(t, newArchive, []archiveMember{{name: "backends.toml", content: []byte(createBackendConfiguration(3))}})
+	require.NoError(t, os.Rename(newArchive, archive))
+
+	assert.Len(t, receiveConfiguration(t, configurationChan).Backends, 3)
+}
+
+// createArchive writes a tar archive of the members, compressed with gzip if its name ends with .gz or .tgz.
+// The members whose name ends with a slash are directories.
+func createArchive(t *testing.T, filename string, members []archiveMember) {
+	t.Helper()
+
+	file, err := os.Create(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var writer io.Writer = file
+	if isGzipArchive(filename) {
+		gzipWriter := gzip.NewWriter(file)
+		defer gzipWriter.Close()
+		writer = gzipWriter
+	}
+
+	tarWriter := tar.NewWriter(writer)
+	defer tarWriter.Close()
+
+	for _, member := range members {
+		header := &tar.Header{Name: member.name, Mode: 0644, Size: int64(len(member.content)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(member.name, "/") {
+			header.Mode = 0755
+			header.Typeflag = tar.TypeDir
+		}
+		require.NoError(t, tarWriter.WriteHeader(header))
+		_, err := tarWriter.Write(member.content)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,280 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+	"gopkg.in/fsnotify.v1"
+)
+
+// fileCache holds the configurations loaded from the files of a directory,
+// so that a change to a single file does not require to load the whole directory again.
+// A fileCache is not modified once loaded, a new one is created on changes.
+type fileCache struct {
+	// files holds the loaded files, in load order.
+	files []string
+	// entries holds the configurations of the loaded files.
+	entries map[string]*cachedFile
+	// directories counts the directories loaded.
+	directories int
+}
+
+// cachedFile is the configuration loaded from a file.
+type cachedFile struct {
+	configuration *types.Configuration
+	// dependencies holds the file, the files it includes and the files read to render it if it is a template.
+	dependencies map[string]struct{}
+	// modTime is the newest modification time of the dependencies.
+	modTime time.Time
+	// origins holds the file defining each frontend and backend, the file or one it includes.
+	origins *configurationOrigins
+	// warnings holds the warnings raised by the loading of the file, collected if they are errors.
+	warnings *warnings
+}
+
+func newFileCache() *fileCache {
+	return &fileCache{entries: make(map[string]*cachedFile)}
+}
+
+func (c *fileCache) add(file string, entry *cachedFile) {
+	c.files = append(c.files, file)
+	c.entries[file] = entry
+}
+
+// dependents returns the cached files loaded from the file name, in load order.
+func (c *fileCache) dependents(name string) []string {
+	name = filepath.Clean(name)
+
+	var dependents []string
+	for _, file := range c.files {
+		if _, exists := c.entries[file].dependencies[name]; exists {
+			dependents = append(dependents, file)
+		}
+	}
+	return dependents
+}
+
+// isCachedDependency returns true if a cached file was loaded from the file name, such as a file read by a template.
+func (p *Provider) isCachedDependency(name string) bool {
+	cache, ok := p.cache.Get().(*fileCache)
+	return ok && len(cache.dependents(name)) > 0
+}
+
+// stats counts the files read to load the cached files, including the files they include, and the directories loaded.
+func (c *fileCache) stats() *loadStats {
+	stats := &loadStats{directories: c.directories}
+
+	files := make(map[string]struct{})
+	for _, entry := range c.entries {
+		for file := range entry.dependencies {
+			files[file] = struct{}{}
+		}
+		if entry.modTime.After(stats.lastModified) {
+			stats.lastModified = entry.modTime
+		}
+	}
+	stats.files = sortedFiles(files)
+	return stats
+}
+
+// sortedFiles returns the files of the set, sorted.
+func sortedFiles(files map[string]struct{}) []string {
+	sorted := make([]string, 0, len(files))
+	for file := range files {
+		sorted = append(sorted, file)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// newestModTime returns the newest modification time of the files, the zero time if none can be read.
+func newestModTime(files map[string]struct{}) time.Time {
+	var newest time.Time
+	for file := range files {
+		if file == stdinFilename {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest
+}
+
+// withEntries returns a copy of the cache in which the given entries replace the existing ones.
+func (c *fileCache) withEntries(entries map[string]*cachedFile) *fileCache {
+	cache := &fileCache{
+		files:       c.files,
+		entries:     make(map[string]*cachedFile, len(c.entries)),
+		directories: c.directories,
+	}
+	for file, entry := range c.entries {
+		cache.entries[file] = entry
+	}
+	for file, entry := range entries {
+		cache.entries[file] = entry
+	}
+	return cache
+}
+
+// reusableEntry returns the cached configuration of the file if neither the file nor the files it was loaded from,
+// such as the files it includes or the files read by its template, are in the subtree,
+// or nil if it has to be loaded again.
+func (c *fileCache) reusableEntry(file, subtree string) *cachedFile {
+	entry, ok := c.entries[file]
+	if !ok {
+		return nil
+	}
+	for dependency := range entry.dependencies {
+		if isInDirectory(dependency, subtree) {
+			return nil
+		}
+	}
+	return entry
+}
+
+// isInDirectory returns true if the path is the directory or is in it or in one of its sub-directories.
+func isInDirectory(name, directory string) bool {
+	relativePath, err := filepath.Rel(directory, name)
+	return err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
+
+// reloadConfiguration loads the configuration again after the event.
+// When loading directories or a list of files, only the files loaded from the changed file are loaded again when possible,
+// or else only the files of the sub-directory in which the change happened.
+func (p *Provider) reloadConfiguration(event fsnotify.Event) (*types.Configuration, error) {
+	configuration, ok := p.loadChangedFile(event)
+	if !ok {
+		configuration, ok = p.loadChangedSubtree(event)
+	}
+	if !ok {
+		return p.buildConfiguration()
+	}
+
+	configuration, err := p.withStaticConfiguration(p.withEntryPointFilter(p.withDefaults(configuration)))
+	if err != nil {
+		return nil, err
+	}
+	return p.finalizeConfiguration(configuration)
+}
+
+// loadChangedFile loads again the cached files loaded from the file changed by the event,
+// and merges them with the other cached files.
+// It returns false if the whole directory has to be loaded again:
+// when the event is neither a write nor a creation, when the changed file is the template values, defaults or TriggerFile,
+// when it is not a cached file, as its position in the load order is unknown, or when it can not be loaded.
+func (p *Provider) loadChangedFile(event fsnotify.Event) (*types.Configuration, bool) {
+	if !(p.hasDirectories() || p.hasFiles()) || event.Op&(fsnotify.Write|fsnotify.Create) == 0 || event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		return nil, false
+	}
+	if p.isTemplateValuesFile(event.Name) || p.isDefaultsFile(event.Name) || p.TriggerFile != "" {
+		return nil, false
+	}
+
+	cache, ok := p.cache.Get().(*fileCache)
+	if !ok {
+		return nil, false
+	}
+
+	dependents := cache.dependents(event.Name)
+	if len(dependents) == 0 {
+		return nil, false
+	}
+
+	entries := make(map[string]*cachedFile, len(dependents))
+	for _, file := range dependents {
+		entry, err := p.loadCachedFile(file)
+		if err != nil {
+			// Let the whole loading report or skip the invalid file
+			return nil, false
+		}
+		entries[file] = entry
+	}
+
+	cache = cache.withEntries(entries)
+	configuration, err := p.mergeFileCache(cache)
+	if err != nil {
+		return nil, false
+	}
+
+	p.cache.Set(cache)
+	p.loadStats.Set(cache.stats())
+	return configuration, true
+}
+
+// changedSubtree returns the directory whose files are loaded again after a change of the path:
+// the path itself if it is a directory, such as a created directory or the directory of coalesced changes,
+// or else the directory in which the path was created, written or removed.
+// It returns false if the whole configuration has to be loaded again:
+// when the path is not in a sub-directory of the configured directories, is one of the files affecting all of them,
+// or is a partial template or another file which is neither loaded nor read by a cached file, as the files reading it are unknown.
+func (p *Provider) changedSubtree(name string) (string, bool) {
+	if !p.hasDirectories() || name == "" || p.TriggerFile != "" {
+		return "", false
+	}
+	if p.isTemplateValuesFile(name) || p.isDefaultsFile(name) || p.isIgnoreFile(name) {
+		return "", false
+	}
+	if isPartialTemplate(name) || !p.isWatchedPath(name) && !p.isCachedDependency(name) {
+		return "", false
+	}
+	if p.mergesFilename() && filepath.Clean(name) == filepath.Clean(p.resolved().filename) {
+		return "", false
+	}
+
+	subtree := filepath.Dir(name)
+	if fileInfo, err := os.Stat(name); err == nil && fileInfo.IsDir() {
+		subtree = name
+	}
+	if p.rootDirectory(subtree) == "" || p.isConfiguredDirectory(subtree) {
+		return "", false
+	}
+	return filepath.Clean(subtree), true
+}
+
+// loadChangedSubtree loads again the files of the sub-directory changed by the event, along with its own sub-directories,
+// and merges them with the cached files of the other directories,
+// so that adding or removing a file deep in a large tree does not parse the whole tree again.
+// It returns false if the whole configuration has to be loaded again:
+// when the change is not in a sub-directory of the configured directories, or when the subtree can not be loaded.
+func (p *Provider) loadChangedSubtree(event fsnotify.Event) (*types.Configuration, bool) {
+	subtree, ok := p.changedSubtree(event.Name)
+	if !ok {
+		return nil, false
+	}
+
+	previous, ok := p.cache.Get().(*fileCache)
+	if !ok {
+		return nil, false
+	}
+
+	cache, err := p.loadDirectoriesReusing(p.directories(), previous, subtree)
+	if err != nil {
+		// Let the whole loading report the error
+		return nil, false
+	}
+	if p.mergesFilename() {
+		if err := p.addFilename(cache); err != nil {
+			return nil, false
+		}
+	}
+
+	configuration, err := p.mergeFileCache(cache)
+	if err != nil {
+		return nil, false
+	}
+
+	log.Debugf("Loaded the files of directory %s again after the change of %s", subtree, event.Name)
+	p.cache.Set(cache)
+	p.loadStats.Set(cache.stats())
+	return configuration, true
+}
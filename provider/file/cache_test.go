@@ -0,0 +1,431 @@
+package file
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/fsnotify.v1"
+)
+
+func TestLoadChangedFile(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		event      func(t *testing.T, tempDir, includeDir string) fsnotify.Event
+		expectedOk bool
+	}{
+		{
+			desc: "write of a loaded file",
+			event: func(t *testing.T, tempDir, _ string) fsnotify.Event {
+				createFile(t, tempDir, "b.toml", backendWithURL("backend2", "http://172.17.0.2:80"))
+				return fsnotify.Event{Name: filepath.Join(tempDir, "b.toml"), Op: fsnotify.Write}
+			},
+			expectedOk: true,
+		},
+		{
+			desc: "creation of a loaded file",
+			event: func(t *testing.T, tempDir, _ string) fsnotify.Event {
+				createFile(t, tempDir, "b.toml", backendWithURL("backend2", "http://172.17.0.2:80"))
+				return fsnotify.Event{Name: filepath.Join(tempDir, "b.toml"), Op: fsnotify.Create}
+			},
+			expectedOk: true,
+		},
+		{
+			desc: "write of an included file",
+			event: func(t *testing.T, _, includeDir string) fsnotify.Event {
+				createFile(t, includeDir, "common.toml", backendWithURL("backend3", "http://172.17.0.3:80"))
+				return fsnotify.Event{Name: filepath.Join(includeDir, "common.toml"), Op: fsnotify.Write}
+			},
+			expectedOk: true,
+		},
+		{
+			desc: "creation of a new file",
+			event: func(t *testing.T, tempDir, _ string) fsnotify.Event {
+				createFile(t, tempDir, "c.toml", backendWithURL("backend4", "http://172.17.0.4:80"))
+				return fsnotify.Event{Name: filepath.Join(tempDir, "c.toml"), Op: fsnotify.Create}
+			},
+		},
+		{
+			desc: "removal of a loaded file",
+			event: func(t *testing.T, tempDir, _ string) fsnotify.Event {
+				os.Remove(filepath.Join(tempDir, "b.toml"))
+				return fsnotify.Event{Name: filepath.Join(tempDir, "b.toml"), Op: fsnotify.Remove}
+			},
+		},
+		{
+			desc: "rename of a directory",
+			event: func(t *testing.T, tempDir, _ string) fsnotify.Event {
+				return fsnotify.Event{Name: filepath.Join(tempDir, "sub"), Op: fsnotify.Rename}
+			},
+		},
+		{
+			desc: "invalid loaded file",
+			event: func(t *testing.T, tempDir, _ string) fsnotify.Event {
+				createFile(t, tempDir, "b.toml", "[backends")
+				return fsnotify.Event{Name: filepath.Join(tempDir, "b.toml"), Op: fsnotify.Write}
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+			includeDir := createTempDir(t, "testinclude")
+			defer os.RemoveAll(includeDir)
+
+			createFile(t, includeDir, "common.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
+			createFile(t, tempDir, "a.toml",
+				fmt.Sprintf("[include]\nfiles = [%q]\n", filepath.Join(includeDir, "common.toml")),
+				backendWithURL("backend1", "http://172.17.0.1:80"))
+			createFile(t, tempDir, "b.toml", backendWithURL("backend2", "http://172.17.0.1:80"))
+
+			pvd := &Provider{Directory: tempDir}
+			_, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			configuration, ok := pvd.loadChangedFile(test.event(t, tempDir, includeDir))
+			require.Equal(t, test.expectedOk, ok)
+			if !test.expectedOk {
+				return
+			}
+
+			expected, err := pvd.loadFileConfigFromDirectory(tempDir)
+			require.NoError(t, err)
+			assert.Equal(t, expected, configuration)
+		})
+	}
+}
+
+func TestLoadChangedSubtree(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		event      func(t *testing.T, tempDir string) fsnotify.Event
+		expectedOk bool
+	}{
+		{
+			desc: "creation of a file in a sub-directory",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				file := createFile(t, filepath.Join(tempDir, "a", "b"), "c.toml", backendWithURL("backend4", "http://172.17.0.4:80"))
+				return fsnotify.Event{Name: file.Name(), Op: fsnotify.Create}
+			},
+			expectedOk: true,
+		},
+		{
+			desc: "removal of a file in a sub-directory",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				name := filepath.Join(tempDir, "a", "b", "b.toml")
+				require.NoError(t, os.Remove(name))
+				return fsnotify.Event{Name: name, Op: fsnotify.Remove}
+			},
+			expectedOk: true,
+		},
+		{
+			desc: "creation of a sub-directory",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				directory := createSubDir(t, filepath.Join(tempDir, "a"), "new")
+				createFile(t, directory, "new.toml", backendWithURL("backend5", "http://172.17.0.5:80"))
+				return fsnotify.Event{Name: directory, Op: fsnotify.Create}
+			},
+			expectedOk: true,
+		},
+		{
+			desc: "removal of a sub-directory",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				directory := filepath.Join(tempDir, "a", "sibling")
+				require.NoError(t, os.RemoveAll(directory))
+				return fsnotify.Event{Name: directory, Op: fsnotify.Remove}
+			},
+			expectedOk: true,
+		},
+		{
+			desc: "write of a file included from another directory",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				name := filepath.Join(tempDir, "a", "b", "common.toml")
+				createFile(t, filepath.Join(tempDir, "a", "b"), "common.toml", backendWithURL("backend6", "http://172.17.0.6:80"))
+				return fsnotify.Event{Name: name, Op: fsnotify.Write}
+			},
+			expectedOk: true,
+		},
+		{
+			desc: "change of a partial template",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				file := createFile(t, filepath.Join(tempDir, "a", "b"), "_partial.tmpl", backendWithURL("backend4", "http://172.17.0.4:80"))
+				return fsnotify.Event{Name: file.Name(), Op: fsnotify.Write}
+			},
+		},
+		{
+			desc: "change of a file neither loaded nor read",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				file := createFile(t, filepath.Join(tempDir, "a", "b"), "servers.csv", "url\n")
+				return fsnotify.Event{Name: file.Name(), Op: fsnotify.Write}
+			},
+		},
+		{
+			desc: "creation of a file in the configured directory",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				file := createFile(t, tempDir, "c.toml", backendWithURL("backend4", "http://172.17.0.4:80"))
+				return fsnotify.Event{Name: file.Name(), Op: fsnotify.Create}
+			},
+		},
+		{
+			desc: "change of the ignore file",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				file := createFile(t, tempDir, ignoreFilename, "a/b/\n")
+				return fsnotify.Event{Name: file.Name(), Op: fsnotify.Write}
+			},
+		},
+		{
+			desc: "whole configuration",
+			event: func(t *testing.T, tempDir string) fsnotify.Event {
+				return fsnotify.Event{}
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			aDir := createSubDir(t, tempDir, "a")
+			subDir := createSubDir(t, aDir, "b")
+			siblingDir := createSubDir(t, aDir, "sibling")
+			createFile(t, tempDir, "a.toml",
+				fmt.Sprintf("[include]\nfiles = [%q]\n", filepath.Join(subDir, "common.toml")),
+				backendWithURL("backend1", "http://172.17.0.1:80"))
+			createFile(t, subDir, "common.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
+			createFile(t, subDir, "b.toml", backendWithURL("backend2", "http://172.17.0.1:80"))
+			createFile(t, siblingDir, "sibling.toml", backendWithURL("sibling", "http://172.17.0.1:80"))
+
+			pvd := &Provider{Directory: tempDir}
+			_, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			event := test.event(t, tempDir)
+			configuration, ok := pvd.loadChangedSubtree(event)
+			require.Equal(t, test.expectedOk, ok)
+			if !test.expectedOk {
+				return
+			}
+
+			expected, err := pvd.loadFileConfigFromDirectory(tempDir)
+			require.NoError(t, err)
+			assert.Equal(t, expected, configuration)
+		})
+	}
+}
+
+func TestLoadChangedSubtreeReusesSiblings(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	subDir := createSubDir(t, tempDir, "changed")
+	siblingDir := createSubDir(t, tempDir, "sibling")
+	createFile(t, subDir, "changed.toml", backendWithURL("changed", "http://172.17.0.1:80"))
+	createFile(t, siblingDir, "sibling.toml", backendWithURL("sibling", "http://172.17.0.1:80"))
+
+	pvd := &Provider{Directory: tempDir}
+	_, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	// The sibling file is modified without being notified, to check it is not parsed again
+	createFile(t, siblingDir, "sibling.toml", backendWithURL("sibling", "http://172.17.0.2:80"))
+	createFile(t, subDir, "new.toml", backendWithURL("new", "http://172.17.0.3:80"))
+
+	configuration, ok := pvd.loadChangedSubtree(fsnotify.Event{Name: filepath.Join(subDir, "new.toml"), Op: fsnotify.Create})
+	require.True(t, ok)
+
+	assert.Len(t, configuration.Backends, 3)
+	assert.Equal(t, "http://172.17.0.1:80", configuration.Backends["sibling"].Servers["server1"].URL)
+	assert.Equal(t, "http://172.17.0.3:80", configuration.Backends["new"].Servers["server1"].URL)
+	assert.Equal(t, []string{
+		filepath.Join(subDir, "changed.toml"),
+		filepath.Join(subDir, "new.toml"),
+		filepath.Join(siblingDir, "sibling.toml"),
+	}, pvd.LoadedFiles())
+}
+
+func TestCoalesceEvents(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	aDir := createSubDir(t, tempDir, "a")
+	abDir := createSubDir(t, aDir, "b")
+	acDir := createSubDir(t, aDir, "c")
+	otherDir := createSubDir(t, tempDir, "other")
+
+	testCases := []struct {
+		desc     string
+		first    fsnotify.Event
+		second   fsnotify.Event
+		expected fsnotify.Event
+	}{
+		{
+			desc:     "same file",
+			first:    fsnotify.Event{Name: filepath.Join(abDir, "x.toml"), Op: fsnotify.Create},
+			second:   fsnotify.Event{Name: filepath.Join(abDir, "x.toml"), Op: fsnotify.Write},
+			expected: fsnotify.Event{Name: filepath.Join(abDir, "x.toml"), Op: fsnotify.Write},
+		},
+		{
+			desc:     "files of the same directory",
+			first:    fsnotify.Event{Name: filepath.Join(abDir, "x.toml"), Op: fsnotify.Create},
+			second:   fsnotify.Event{Name: filepath.Join(abDir, "y.toml"), Op: fsnotify.Remove},
+			expected: fsnotify.Event{Name: abDir, Op: fsnotify.Create},
+		},
+		{
+			desc:     "files of sibling directories",
+			first:    fsnotify.Event{Name: filepath.Join(abDir, "x.toml"), Op: fsnotify.Write},
+			second:   fsnotify.Event{Name: filepath.Join(acDir, "y.toml"), Op: fsnotify.Write},
+			expected: fsnotify.Event{Name: aDir, Op: fsnotify.Create},
+		},
+		{
+			desc:   "files of distinct top-level directories",
+			first:  fsnotify.Event{Name: filepath.Join(abDir, "x.toml"), Op: fsnotify.Write},
+			second: fsnotify.Event{Name: filepath.Join(otherDir, "y.toml"), Op: fsnotify.Write},
+		},
+		{
+			desc:   "file of the configured directory",
+			first:  fsnotify.Event{Name: filepath.Join(abDir, "x.toml"), Op: fsnotify.Write},
+			second: fsnotify.Event{Name: filepath.Join(tempDir, "y.toml"), Op: fsnotify.Write},
+		},
+		{
+			desc:   "whole configuration",
+			first:  fsnotify.Event{},
+			second: fsnotify.Event{Name: filepath.Join(abDir, "x.toml"), Op: fsnotify.Write},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pvd := &Provider{Directory: tempDir}
+			assert.Equal(t, test.expected, pvd.coalesceEvents(test.first, test.second))
+		})
+	}
+}
+
+func BenchmarkBuildConfiguration(b *testing.B) {
+	tempDir, changedFile := createBenchmarkDirectory(b, 200)
+	defer os.RemoveAll(tempDir)
+
+	pvd := &Provider{Directory: tempDir}
+	for i := 0; i < b.N; i++ {
+		if _, err := pvd.reloadConfiguration(fsnotify.Event{Name: changedFile, Op: fsnotify.Remove}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadChangedFile(b *testing.B) {
+	tempDir, changedFile := createBenchmarkDirectory(b, 200)
+	defer os.RemoveAll(tempDir)
+
+	pvd := &Provider{Directory: tempDir}
+	if _, err := pvd.BuildConfiguration(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pvd.reloadConfiguration(fsnotify.Event{Name: changedFile, Op: fsnotify.Write}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// createBenchmarkDirectory Helper
+func createBenchmarkDirectory(b *testing.B, n int) (string, string) {
+	b.Helper()
+
+	tempDir, err := ioutil.TempDir("", "benchdir")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var file string
+	for i := 0; i < n; i++ {
+		file = filepath.Join(tempDir, fmt.Sprintf("file%03d.toml", i))
+		content := backendWithURL(fmt.Sprintf("backend%d", i), "http://172.17.0.1:80") +
+			fmt.Sprintf("[frontends.frontend%d]\nbackend = \"backend%d\"\n", i, i)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return tempDir, file
+}
+
+// backendWithURL Helper
+func backendWithURL(name, url string) string {
+	return fmt.Sprintf("[backends.%s.servers.server1]\nurl = %q\n", name, url)
+}
+
+func BenchmarkBuildConfigurationTree(b *testing.B) {
+	tempDir, _ := createBenchmarkTree(b, 20, 10)
+	defer os.RemoveAll(tempDir)
+
+	pvd := &Provider{Directory: tempDir}
+	for i := 0; i < b.N; i++ {
+		if _, err := pvd.reloadConfiguration(fsnotify.Event{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadChangedSubtree(b *testing.B) {
+	tempDir, changedFile := createBenchmarkTree(b, 20, 10)
+	defer os.RemoveAll(tempDir)
+
+	pvd := &Provider{Directory: tempDir}
+	if _, err := pvd.BuildConfiguration(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pvd.reloadConfiguration(fsnotify.Event{Name: changedFile, Op: fsnotify.Remove}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// createBenchmarkTree creates a tree of directories holding each a sub-directory of files,
+// and returns its path along with the last file created.
+func createBenchmarkTree(b *testing.B, directories, files int) (string, string) {
+	b.Helper()
+
+	tempDir, err := ioutil.TempDir("", "benchtree")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var file string
+	for i := 0; i < directories; i++ {
+		directory := filepath.Join(tempDir, fmt.Sprintf("dir%02d", i), "sub")
+		if err := os.MkdirAll(directory, 0755); err != nil {
+			b.Fatal(err)
+		}
+		for j := 0; j < files; j++ {
+			n := i*files + j
+			file = filepath.Join(directory, fmt.Sprintf("file%03d.toml", j))
+			content := backendWithURL(fmt.Sprintf("backend%d", n), "http://172.17.0.1:80") +
+				fmt.Sprintf("[frontends.frontend%d]\nbackend = \"backend%d\"\n", n, n)
+			if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	return tempDir, file
+}
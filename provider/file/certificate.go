@@ -0,0 +1,149 @@
+package file
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/log"
+	traefikTls "github.com/containous/traefik/tls"
+	"github.com/containous/traefik/types"
+)
+
+// checkCertificatesExpiry logs a warning for each certificate of the configuration which expires within CertExpiryWarning.
+func (p *Provider) checkCertificatesExpiry(configuration *types.Configuration) {
+	if p.CertExpiryWarning <= 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, conf := range configuration.TLSConfiguration {
+		if conf.Certificate == nil {
+			continue
+		}
+
+		cert, err := parseCertificate(conf.Certificate.CertFile)
+		if err != nil {
+			log.Warnf("Unable to check the expiry of the certificate %s: %v", certificateSource(conf.Certificate.CertFile), err)
+			continue
+		}
+
+		if warning := certificateExpiryWarning(cert, now, time.Duration(p.CertExpiryWarning)); warning != "" {
+			log.Warn(warning)
+		}
+	}
+}
+
+// checkKeyPairs checks that the certificate and key of each TLS configuration of the file are readable and form a valid key pair,
+// as they are loaded by the entry points, so that a mispaired certificate is reported along with the file declaring it.
+// The invalid key pairs are logged as errors, or returned as an error with StrictValidation.
+func (p *Provider) checkKeyPairs(filename string, configuration *types.Configuration) error {
+	var problems []string
+	for index, conf := range configuration.TLSConfiguration {
+		if conf.Certificate == nil {
+			continue
+		}
+		if err := loadKeyPair(conf.Certificate); err != nil {
+			problems = append(problems, fmt.Sprintf("TLS configuration %d of entry points %v of %s has an invalid key pair (certificate %s): %v",
+				index, conf.EntryPoints, filename, certificateSource(conf.Certificate.CertFile), err))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if p.StrictValidation {
+		return invalidConfigurationError(problems)
+	}
+
+	for _, problem := range problems {
+		log.Errorf("Invalid configuration: %s", problem)
+	}
+	return nil
+}
+
+// loadKeyPair reads and parses the certificate and key, checking that the key matches the certificate.
+func loadKeyPair(certificate *traefikTls.Certificate) error {
+	certContent, err := certificate.CertFile.Read()
+	if err != nil {
+		return fmt.Errorf("unable to read the certificate: %v", err)
+	}
+	keyContent, err := certificate.KeyFile.Read()
+	if err != nil {
+		return fmt.Errorf("unable to read the key: %v", err)
+	}
+
+	_, err = tls.X509KeyPair(certContent, keyContent)
+	return err
+}
+
+// certificateExpiryWarning returns a warning if the certificate expired or expires within the threshold, and an empty string otherwise.
+func certificateExpiryWarning(cert *x509.Certificate, now time.Time, threshold time.Duration) string {
+	domains := cert.Subject.CommonName
+	if len(cert.DNSNames) > 0 {
+		domains += " (" + strings.Join(cert.DNSNames, ", ") + ")"
+	}
+	expiry := cert.NotAfter.UTC().Format(time.RFC3339)
+
+	switch {
+	case !now.Before(cert.NotAfter):
+		return fmt.Sprintf("Certificate for %s expired on %s", domains, expiry)
+	case cert.NotAfter.Before(now.Add(threshold)):
+		return fmt.Sprintf("Certificate for %s expires on %s", domains, expiry)
+	}
+	return ""
+}
+
+// parseCertificate parses the first certificate of the PEM content, or file.
+func parseCertificate(certFile traefikTls.FileOrContent) (*x509.Certificate, error) {
+	content, err := certFile.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// certificateSource describes the certificate file, without printing the content of inline certificates.
+func certificateSource(certFile traefikTls.FileOrContent) string {
+	if certFile.IsPath() {
+		return certFile.String()
+	}
+	return "defined inline"
+}
+
+// resolveCertificatePaths makes the relative certificate and key paths of the TLS configurations
+// relative to the directory of the file declaring them, when the file exists there.
+// Absolute paths, inline contents and paths only found relative to the working directory are kept.
+func resolveCertificatePaths(filename string, configuration *types.Configuration) {
+	for _, conf := range configuration.TLSConfiguration {
+		if conf.Certificate == nil {
+			continue
+		}
+		conf.Certificate.CertFile = resolveCertificatePath(filename, conf.Certificate.CertFile)
+		conf.Certificate.KeyFile = resolveCertificatePath(filename, conf.Certificate.KeyFile)
+	}
+}
+
+func resolveCertificatePath(filename string, certFile traefikTls.FileOrContent) traefikTls.FileOrContent {
+	value := certFile.String()
+	if value == "" || filepath.IsAbs(value) || strings.ContainsAny(value, "\n") {
+		return certFile
+	}
+
+	path := resolvePath(filename, value)
+	if _, err := os.Stat(path); err != nil {
+		return certFile
+	}
+	return traefikTls.FileOrContent(path)
+}
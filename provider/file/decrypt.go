@@ -0,0 +1,37 @@
+package file
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// encryptedMarker ends the name of the encrypted configuration files before their extension, such as rules.enc.toml.
+const encryptedMarker = ".enc"
+
+// sopsEnvelope matches the top-level sops key holding the metadata of the files encrypted with SOPS,
+// as a YAML key, a TOML table or a JSON key.
+var sopsEnvelope = regexp.MustCompile(`(?m)^(sops:|\[sops\]|\s*"sops"\s*:)`)
+
+// isEncryptedFile returns true if the configuration file is encrypted:
+// its name ends with the encrypted marker before its extensions, or its content has a SOPS envelope.
+func isEncryptedFile(filename string, content []byte) bool {
+	name := strings.TrimSuffix(uncompressedName(filepath.Base(filename)), templateExtension)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.HasSuffix(name, encryptedMarker) || sopsEnvelope.Match(content)
+}
+
+// decrypt returns the content of the configuration file decrypted by the Decryptor if the file is encrypted,
+// before it is rendered or decoded. The content is returned unchanged without Decryptor.
+func (p *Provider) decrypt(filename string, content []byte) ([]byte, error) {
+	if p.Decryptor == nil || !isEncryptedFile(filename, content) {
+		return content, nil
+	}
+
+	decrypted, err := p.Decryptor(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt: %v", err)
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,104 @@
+package file
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsEncryptedFile(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filename string
+		content  string
+		expected bool
+	}{
+		{
+			desc:     "plain file",
+			filename: "rules.toml",
+			content:  "[backends]\n",
+		},
+		{
+			desc:     "encrypted marker",
+			filename: "rules.enc.toml",
+			expected: true,
+		},
+		{
+			desc:     "encrypted compressed template",
+			filename: "rules.enc.yml.tmpl.gz",
+			expected: true,
+		},
+		{
+			desc:     "marker in the middle of the name",
+			filename: "rules.encoded.toml",
+		},
+		{
+			desc:     "SOPS YAML envelope",
+			filename: "rules.yml",
+			content:  "backends: ENC[AES256_GCM,data:abc]\nsops:\n  version: 3.0.0\n",
+			expected: true,
+		},
+		{
+			desc:     "SOPS JSON envelope",
+			filename: "rules.json",
+			content:  "{\n  \"backends\": \"ENC[AES256_GCM,data:abc]\",\n  \"sops\": {}\n}\n",
+			expected: true,
+		},
+		{
+			desc:     "SOPS TOML envelope",
+			filename: "rules.toml",
+			content:  "backends = \"ENC[AES256_GCM,data:abc]\"\n[sops]\nversion = \"3.0.0\"\n",
+			expected: true,
+		},
+		{
+			desc:     "nested sops key",
+			filename: "rules.yml",
+			content:  "backends:\n  sops:\n    servers: {}\n",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, isEncryptedFile(test.filename, []byte(test.content)))
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryDecryptor(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.enc.toml", base64.StdEncoding.EncodeToString([]byte(createBackendConfiguration(2))))
+	createFile(t, tempDir, "frontends.toml", createFrontendConfiguration(2))
+
+	pvd := &Provider{Decryptor: func(content []byte) ([]byte, error) {
+		return base64.StdEncoding.DecodeString(string(content))
+	}}
+	configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+	require.NoError(t, err)
+
+	assert.Len(t, configuration.Backends, 2)
+	assert.Len(t, configuration.Frontends, 2)
+}
+
+func TestLoadFileConfigDecryptorError(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	tempFile := createFile(t, tempDir, "rules.enc.toml", "secret")
+
+	pvd := &Provider{Decryptor: func([]byte) ([]byte, error) {
+		return nil, errors.New("no key available")
+	}}
+	_, err := pvd.loadFileConfig(tempFile.Name())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), tempFile.Name())
+	assert.Contains(t, err.Error(), "unable to decrypt: no key available")
+}
@@ -0,0 +1,93 @@
+package file
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+
+	"github.com/containous/traefik/types"
+)
+
+// fileDefaults holds the default settings of the DefaultsFile,
+// applied to the backends and frontends which do not set them.
+type fileDefaults struct {
+	Backend  *types.Backend  `json:"backend,omitempty"`
+	Frontend *types.Frontend `json:"frontend,omitempty"`
+}
+
+// loadDefaults reads the DefaultsFile, whose settings are applied to the backends and frontends of the configuration.
+func (p *Provider) loadDefaults() error {
+	if p.DefaultsFile == "" {
+		p.defaults.Set(nil)
+		return nil
+	}
+
+	content, err := readFile(p.DefaultsFile)
+	if err != nil {
+		return fmt.Errorf("error reading defaults file %s: %v", p.DefaultsFile, err)
+	}
+
+	// Files without a known extension are read as TOML, like the configuration files
+	f, ok := formatFromFilename(p.DefaultsFile)
+	if !ok {
+		f = formatTOML
+	}
+
+	defaults := &fileDefaults{}
+	if err := decode(content, f, defaults); err != nil {
+		return fmt.Errorf("error reading defaults file %s: unable to decode %s defaults: %v", p.DefaultsFile, f, err)
+	}
+
+	p.defaults.Set(defaults)
+	return nil
+}
+
+// isDefaultsFile returns true if the path is the DefaultsFile.
+func (p *Provider) isDefaultsFile(name string) bool {
+	return p.DefaultsFile != "" && filepath.Clean(name) == filepath.Clean(p.DefaultsFile)
+}
+
+// withDefaults returns a copy of the configuration in which the settings of the DefaultsFile fill the unset settings
+// of each backend and frontend. The settings set to their zero value, such as false, are considered unset.
+// The configuration itself is left unchanged, as its backends and frontends may be cached.
+func (p *Provider) withDefaults(configuration *types.Configuration) *types.Configuration {
+	defaults, ok := p.defaults.Get().(*fileDefaults)
+	if !ok {
+		return configuration
+	}
+
+	filled := &types.Configuration{
+		Frontends:        make(map[string]*types.Frontend, len(configuration.Frontends)),
+		Backends:         make(map[string]*types.Backend, len(configuration.Backends)),
+		TLSConfiguration: configuration.TLSConfiguration,
+	}
+	for backendName, backend := range configuration.Backends {
+		if defaults.Backend != nil {
+			backend = withDefaultFields(backend, defaults.Backend).(*types.Backend)
+		}
+		filled.Backends[backendName] = backend
+	}
+	for frontendName, frontend := range configuration.Frontends {
+		if defaults.Frontend != nil {
+			frontend = withDefaultFields(frontend, defaults.Frontend).(*types.Frontend)
+		}
+		filled.Frontends[frontendName] = frontend
+	}
+	return filled
+}
+
+// withDefaultFields returns a copy of the struct pointed to by value,
+// in which the fields set to their zero value are set to the ones of the struct pointed to by defaults.
+func withDefaultFields(value, defaults interface{}) interface{} {
+	filled := reflect.New(reflect.TypeOf(value).Elem())
+	filled.Elem().Set(reflect.ValueOf(value).Elem())
+
+	defaultValues := reflect.ValueOf(defaults).Elem()
+	for i := 0; i < filled.Elem().NumField(); i++ {
+		field := filled.Elem().Field(i)
+		if reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()) {
+			field.Set(defaultValues.Field(i))
+		}
+	}
+	return filled.Interface()
+}
@@ -0,0 +1,148 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/fsnotify.v1"
+)
+
+func TestBuildConfigurationDefaults(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		defaultsFile string
+		defaults     string
+	}{
+		{
+			desc:         "TOML defaults",
+			defaultsFile: "defaults.toml",
+			defaults: `
+[backend]
+  [backend.loadBalancer]
+  method = "drr"
+  [backend.healthCheck]
+  path = "/health"
+  interval = "10s"
+
+[frontend]
+entryPoints = ["http"]
+passHostHeader = true
+`,
+		},
+		{
+			desc:         "YAML defaults",
+			defaultsFile: "defaults.yml",
+			defaults: `
+backend:
+  loadBalancer:
+    method: drr
+  healthCheck:
+    path: /health
+    interval: 10s
+frontend:
+  entryPoints:
+  - http
+  passHostHeader: true
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			settingsDir := createSubDir(t, tempDir, "settings")
+			defaultsFile := createFile(t, settingsDir, test.defaultsFile, test.defaults)
+			rulesDir := createSubDir(t, tempDir, "rules")
+			createFile(t, rulesDir, "rules.toml", `
+[backends.backend1.servers.server1]
+url = "http://10.0.0.1:80"
+
+[backends.backend2.loadBalancer]
+method = "wrr"
+[backends.backend2.servers.server1]
+url = "http://10.0.0.2:80"
+
+[frontends.frontend1]
+backend = "backend1"
+
+[frontends.frontend2]
+backend = "backend2"
+entryPoints = ["https"]
+`)
+
+			pvd := &Provider{Directory: rulesDir, DefaultsFile: defaultsFile.Name()}
+			configuration, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			expectedHealthCheck := &types.HealthCheck{Path: "/health", Interval: "10s"}
+
+			backend1 := configuration.Backends["backend1"]
+			require.NotNil(t, backend1)
+			assert.Equal(t, &types.LoadBalancer{Method: "drr"}, backend1.LoadBalancer)
+			assert.Equal(t, expectedHealthCheck, backend1.HealthCheck)
+			assert.Equal(t, "http://10.0.0.1:80", backend1.Servers["server1"].URL)
+
+			backend2 := configuration.Backends["backend2"]
+			require.NotNil(t, backend2)
+			assert.Equal(t, &types.LoadBalancer{Method: "wrr"}, backend2.LoadBalancer)
+			assert.Equal(t, expectedHealthCheck, backend2.HealthCheck)
+
+			frontend1 := configuration.Frontends["frontend1"]
+			require.NotNil(t, frontend1)
+			assert.Equal(t, []string{"http"}, frontend1.EntryPoints)
+			assert.True(t, frontend1.PassHostHeader)
+			assert.Equal(t, "backend1", frontend1.Backend)
+
+			frontend2 := configuration.Frontends["frontend2"]
+			require.NotNil(t, frontend2)
+			assert.Equal(t, []string{"https"}, frontend2.EntryPoints)
+			assert.True(t, frontend2.PassHostHeader)
+
+			// The cached configuration of the file is left unchanged
+			cache, ok := pvd.cache.Get().(*fileCache)
+			require.True(t, ok)
+			cached := cache.entries[filepath.Join(rulesDir, "rules.toml")].configuration
+			assert.Nil(t, cached.Backends["backend1"].LoadBalancer)
+			assert.Nil(t, cached.Frontends["frontend1"].EntryPoints)
+		})
+	}
+}
+
+func TestBuildConfigurationInvalidDefaults(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	defaultsFile := createFile(t, tempDir, "defaults.toml", "[backend\n")
+	rulesFile := createFile(t, tempDir, "rules.toml", createBackendConfiguration(1))
+
+	pvd := &Provider{DefaultsFile: defaultsFile.Name()}
+	pvd.Filename = rulesFile.Name()
+	_, err := pvd.BuildConfiguration()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), defaultsFile.Name())
+}
+
+func TestWatchedDirectoriesDefaultsFile(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	rulesDir := createSubDir(t, tempDir, "rules")
+	defaultsFile := filepath.Join(tempDir, "defaults.toml")
+
+	pvd := &Provider{Directory: rulesDir, DefaultsFile: defaultsFile}
+	directories, err := pvd.watchedDirectories()
+	require.NoError(t, err)
+	assert.Equal(t, []string{rulesDir, tempDir}, directories)
+
+	assert.True(t, pvd.isWatchedEvent(fsnotify.Event{Name: defaultsFile, Op: fsnotify.Write}))
+	assert.False(t, pvd.isWatchedEvent(fsnotify.Event{Name: filepath.Join(tempDir, "notes.txt"), Op: fsnotify.Write}))
+}
@@ -0,0 +1,92 @@
+package file
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+)
+
+// nameDiff holds the names added, removed and modified between two configurations, sorted.
+type nameDiff struct {
+	added    []string
+	removed  []string
+	modified []string
+}
+
+func (d nameDiff) isEmpty() bool {
+	return len(d.added) == 0 && len(d.removed) == 0 && len(d.modified) == 0
+}
+
+func (d nameDiff) String() string {
+	var parts []string
+	if len(d.added) > 0 {
+		parts = append(parts, "added "+strings.Join(d.added, ", "))
+	}
+	if len(d.removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(d.removed, ", "))
+	}
+	if len(d.modified) > 0 {
+		parts = append(parts, "modified "+strings.Join(d.modified, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// logConfigurationDiff logs the frontends and backends which changed between the previous and the new configuration.
+func logConfigurationDiff(previous, configuration *types.Configuration) {
+	if frontends := diffFrontends(previous.Frontends, configuration.Frontends); !frontends.isEmpty() {
+		log.Infof("File configuration frontends changed: %s", frontends)
+	}
+	if backends := diffBackends(previous.Backends, configuration.Backends); !backends.isEmpty() {
+		log.Infof("File configuration backends changed: %s", backends)
+	}
+}
+
+func diffFrontends(previous, current map[string]*types.Frontend) nameDiff {
+	previousValues := make(map[string]interface{}, len(previous))
+	for name, frontend := range previous {
+		previousValues[name] = frontend
+	}
+	currentValues := make(map[string]interface{}, len(current))
+	for name, frontend := range current {
+		currentValues[name] = frontend
+	}
+	return diffNames(previousValues, currentValues)
+}
+
+func diffBackends(previous, current map[string]*types.Backend) nameDiff {
+	previousValues := make(map[string]interface{}, len(previous))
+	for name, backend := range previous {
+		previousValues[name] = backend
+	}
+	currentValues := make(map[string]interface{}, len(current))
+	for name, backend := range current {
+		currentValues[name] = backend
+	}
+	return diffNames(previousValues, currentValues)
+}
+
+func diffNames(previous, current map[string]interface{}) nameDiff {
+	var diff nameDiff
+	for name, value := range current {
+		previousValue, exists := previous[name]
+		switch {
+		case !exists:
+			diff.added = append(diff.added, name)
+		case !reflect.DeepEqual(previousValue, value):
+			diff.modified = append(diff.modified, name)
+		}
+	}
+	for name := range previous {
+		if _, exists := current[name]; !exists {
+			diff.removed = append(diff.removed, name)
+		}
+	}
+
+	sort.Strings(diff.added)
+	sort.Strings(diff.removed)
+	sort.Strings(diff.modified)
+	return diff
+}
@@ -0,0 +1,75 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffBackends(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		previous map[string]*types.Backend
+		current  map[string]*types.Backend
+		expected nameDiff
+	}{
+		{
+			desc: "no change",
+			previous: map[string]*types.Backend{
+				"backend1": {Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.1:80"}}},
+			},
+			current: map[string]*types.Backend{
+				"backend1": {Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.1:80"}}},
+			},
+		},
+		{
+			desc: "added, removed and modified backends",
+			previous: map[string]*types.Backend{
+				"backend1": {Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.1:80"}}},
+				"backend2": {Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.2:80"}}},
+				"backend3": {Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.3:80"}}},
+			},
+			current: map[string]*types.Backend{
+				"backend1": {Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.1:80"}}},
+				"backend3": {Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.4:80"}}},
+				"backend5": {Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.5:80"}}},
+				"backend4": {Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.4:80"}}},
+			},
+			expected: nameDiff{
+				added:    []string{"backend4", "backend5"},
+				removed:  []string{"backend2"},
+				modified: []string{"backend3"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, diffBackends(test.previous, test.current))
+		})
+	}
+}
+
+func TestDiffFrontends(t *testing.T) {
+	previous := map[string]*types.Frontend{
+		"frontend1": {Backend: "backend1"},
+		"frontend2": {Backend: "backend2"},
+	}
+	current := map[string]*types.Frontend{
+		"frontend1": {Backend: "backend3"},
+		"frontend3": {Backend: "backend3"},
+	}
+
+	diff := diffFrontends(previous, current)
+
+	assert.Equal(t, nameDiff{
+		added:    []string{"frontend3"},
+		removed:  []string{"frontend2"},
+		modified: []string{"frontend1"},
+	}, diff)
+	assert.Equal(t, "added frontend3; removed frontend2; modified frontend1", diff.String())
+}
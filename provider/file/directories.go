@@ -0,0 +1,86 @@
+package file
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/containous/traefik/log"
+)
+
+// directories returns the configured directories, in load order: the Directory, the Directories, then the OverrideDirectory.
+func (p *Provider) directories() []string {
+	var directories []string
+	paths := p.resolved()
+	if paths.directory != "" {
+		directories = append(directories, paths.directory)
+	}
+	directories = append(directories, paths.directories...)
+	if paths.overrideDirectory != "" {
+		directories = append(directories, paths.overrideDirectory)
+	}
+	return directories
+}
+
+// hasDirectories returns true if the configuration is loaded from directories rather than from a file.
+func (p *Provider) hasDirectories() bool {
+	paths := p.resolved()
+	return paths.directory != "" || len(paths.directories) > 0 || paths.overrideDirectory != ""
+}
+
+// mergesFilename returns true if the Filename is loaded after the directories, with MergeFilename.
+func (p *Provider) mergesFilename() bool {
+	return p.MergeFilename && p.hasDirectories() && p.resolved().filename != ""
+}
+
+// addFilename loads the Filename into the cache of the directories, after their files, unless it is one of them.
+func (p *Provider) addFilename(cache *fileCache) error {
+	filename := filepath.Clean(p.resolved().filename)
+	if _, exists := cache.entries[filename]; exists {
+		log.Debugf("File %s already loaded from the directories", filename)
+		return nil
+	}
+
+	entry, err := p.loadCachedFile(p.resolved().filename)
+	if err != nil {
+		return err
+	}
+	cache.add(filename, entry)
+	return nil
+}
+
+// isOverrideDirectory returns true if the path is the OverrideDirectory.
+func (p *Provider) isOverrideDirectory(name string) bool {
+	overrideDirectory := p.resolved().overrideDirectory
+	return overrideDirectory != "" && filepath.Clean(name) == filepath.Clean(overrideDirectory)
+}
+
+// isOverrideFile returns true if the file is loaded from the OverrideDirectory.
+func (p *Provider) isOverrideFile(name string) bool {
+	return p.resolved().overrideDirectory != "" && p.isOverrideDirectory(p.rootDirectory(name))
+}
+
+// isConfiguredDirectory returns true if the path is one of the configured directories.
+func (p *Provider) isConfiguredDirectory(name string) bool {
+	for _, directory := range p.directories() {
+		if filepath.Clean(directory) == filepath.Clean(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// rootDirectory returns the configured directory containing the path, the deepest one if they are nested,
+// and an empty string if none contains it.
+func (p *Provider) rootDirectory(name string) string {
+	var root string
+	for _, directory := range p.directories() {
+		relativePath, err := filepath.Rel(directory, name)
+		if err != nil || strings.HasPrefix(relativePath, "..") {
+			continue
+		}
+		if len(directory) > len(root) {
+			root = directory
+		}
+	}
+	return root
+}
@@ -0,0 +1,265 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/fsnotify.v1"
+)
+
+func TestRootDirectory(t *testing.T) {
+	pvd := &Provider{
+		Directory:   "/etc/traefik/base",
+		Directories: StringList{"/etc/traefik/overlays", "/etc/traefik/overlays/staging"},
+	}
+
+	testCases := []struct {
+		desc     string
+		name     string
+		expected string
+	}{
+		{
+			desc:     "configured directory",
+			name:     "/etc/traefik/base",
+			expected: "/etc/traefik/base",
+		},
+		{
+			desc:     "file of the directory",
+			name:     "/etc/traefik/base/sub/rules.toml",
+			expected: "/etc/traefik/base",
+		},
+		{
+			desc:     "file of nested directories",
+			name:     "/etc/traefik/overlays/staging/rules.toml",
+			expected: "/etc/traefik/overlays/staging",
+		},
+		{
+			desc:     "file of the parent of nested directories",
+			name:     "/etc/traefik/overlays/rules.toml",
+			expected: "/etc/traefik/overlays",
+		},
+		{
+			desc: "file outside of the directories",
+			name: "/etc/traefik/traefik.toml",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, pvd.rootDirectory(test.name))
+		})
+	}
+}
+
+func TestBuildConfigurationDirectories(t *testing.T) {
+	baseDir := createTempDir(t, "testbase")
+	defer os.RemoveAll(baseDir)
+	overlayDir := createTempDir(t, "testoverlay")
+	defer os.RemoveAll(overlayDir)
+
+	createFile(t, baseDir, ignoreFilename, "*.draft.toml\n")
+	createFile(t, baseDir, "b.toml", backendWithURL("backend1", "http://172.17.0.1:80"), backendWithURL("backend2", "http://172.17.0.1:80"))
+	createFile(t, baseDir, "c.draft.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
+	// Loaded after the base directory, whatever its name
+	createFile(t, overlayDir, "a.toml", backendWithURL("backend2", "http://172.17.0.2:80"))
+	createFile(t, overlayDir, "c.draft.toml", backendWithURL("backend4", "http://172.17.0.1:80"))
+
+	pvd := &Provider{
+		Directories:   StringList{baseDir, overlayDir},
+		MergeStrategy: mergeStrategyReplace,
+	}
+
+	configuration, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	require.Len(t, configuration.Backends, 3)
+	assert.Contains(t, configuration.Backends, "backend1")
+	assert.Contains(t, configuration.Backends, "backend4")
+	assert.Equal(t, "http://172.17.0.2:80", configuration.Backends["backend2"].Servers["server1"].URL)
+
+	directories, err := pvd.watchedDirectories()
+	require.NoError(t, err)
+	assert.Equal(t, []string{baseDir, overlayDir}, directories)
+
+	assert.True(t, pvd.isWatchedPath(filepath.Join(overlayDir, "d.toml")))
+	assert.False(t, pvd.isWatchedPath(filepath.Join(baseDir, "d.draft.toml")))
+	assert.True(t, pvd.isWatchedPath(filepath.Join(overlayDir, "d.draft.toml")))
+}
+
+func TestProvideDirectoriesAndWatch(t *testing.T) {
+	baseDir := createTempDir(t, "testbase")
+	defer os.RemoveAll(baseDir)
+	overlayDir := createTempDir(t, "testoverlay")
+	defer os.RemoveAll(overlayDir)
+
+	createFile(t, baseDir, "frontends.toml", createFrontendConfiguration(2))
+	createFile(t, overlayDir, "backends.toml", createBackendConfiguration(2))
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.Directories = StringList{baseDir, overlayDir}
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	require.NoError(t, err)
+
+	expectedNumBackends = 3
+	createFile(t, overlayDir, "backends.toml", createBackendConfiguration(3))
+
+	err = waitForSignal(signal, 2*time.Second, "config of the overlay directory")
+	assert.NoError(t, err)
+}
+
+func TestBuildConfigurationDirectoryIsFile(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	tempFile := createFile(t, tempDir, "rules.toml", createBackendConfiguration(1))
+
+	pvd := &Provider{Directory: tempFile.Name()}
+	_, err := pvd.BuildConfiguration()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("directory %q is not a directory", tempFile.Name()))
+}
+
+func TestBuildConfigurationOverrideDirectory(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		nested bool
+	}{
+		{
+			desc: "override directory next to the directory",
+		},
+		{
+			desc:   "override directory inside the directory",
+			nested: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			baseDir := createTempDir(t, "testbase")
+			defer os.RemoveAll(baseDir)
+
+			overrideDir := createTempDir(t, "testoverride")
+			defer os.RemoveAll(overrideDir)
+			if test.nested {
+				// The override directory sorts before the other sub-directory, but is still loaded last
+				overrideDir = createSubDir(t, baseDir, "0-override")
+			}
+
+			createFile(t, baseDir, "backends.toml", createBackendConfiguration(2)+createFrontendConfiguration(2))
+			createFile(t, createSubDir(t, baseDir, "sub"), "extra.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
+			overrideFile := createFile(t, overrideDir, "backends.toml",
+				backendWithURL("backend1", "http://172.17.0.2:80")+backendWithURL("backend3", "http://172.17.0.2:80"))
+
+			pvd := &Provider{
+				Directory:         baseDir,
+				OverrideDirectory: overrideDir,
+				WarningsAsErrors:  true,
+			}
+			configuration, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			assert.Len(t, configuration.Backends, 3)
+			assert.Len(t, configuration.Frontends, 2)
+			for _, backendName := range []string{"backend1", "backend3"} {
+				require.Contains(t, configuration.Backends, backendName)
+				assert.Equal(t, "http://172.17.0.2:80", configuration.Backends[backendName].Servers["server1"].URL)
+				assert.Equal(t, overrideFile.Name(), pvd.BackendFile(backendName))
+			}
+		})
+	}
+}
+
+func TestBuildConfigurationMergeFilename(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		mergeFilename       bool
+		insideDirectory     bool
+		expectedNumBackends int
+	}{
+		{
+			desc:                "filename ignored",
+			expectedNumBackends: 2,
+		},
+		{
+			desc:                "filename merged",
+			mergeFilename:       true,
+			expectedNumBackends: 3,
+		},
+		{
+			desc:                "filename of the directory loaded once",
+			mergeFilename:       true,
+			insideDirectory:     true,
+			expectedNumBackends: 3,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			rulesDir := createSubDir(t, tempDir, "rules")
+			createFile(t, rulesDir, "backends.toml", createBackendConfiguration(2))
+
+			filenameDir := tempDir
+			if test.insideDirectory {
+				filenameDir = rulesDir
+			}
+			extraFile := createFile(t, filenameDir, "extra.toml", `
+[backends.extra.servers.server1]
+url = "http://10.0.0.1:80"
+`)
+
+			pvd := &Provider{Directory: rulesDir, MergeFilename: test.mergeFilename, WarningsAsErrors: true}
+			pvd.Filename = extraFile.Name()
+			configuration, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			assert.Len(t, configuration.Backends, test.expectedNumBackends)
+			if test.mergeFilename {
+				assert.Equal(t, extraFile.Name(), pvd.BackendFile("extra"))
+			}
+		})
+	}
+}
+
+func TestWatchedDirectoriesMergeFilename(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	rulesDir := createSubDir(t, tempDir, "rules")
+	filename := filepath.Join(tempDir, "traefik.conf")
+
+	pvd := &Provider{Directory: rulesDir, MergeFilename: true}
+	pvd.Filename = filename
+
+	directories, err := pvd.watchedDirectories()
+	require.NoError(t, err)
+	assert.Equal(t, []string{rulesDir, tempDir}, directories)
+
+	assert.True(t, pvd.isWatchedEvent(fsnotify.Event{Name: filename, Op: fsnotify.Write}))
+	assert.Equal(t, fmt.Sprintf("directory %q and file %q", rulesDir, filename), pvd.configurationSource())
+}
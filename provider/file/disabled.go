@@ -0,0 +1,32 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containous/traefik/types"
+)
+
+// disabledMarkerExtension is the extension of the marker files disabling the configuration file of the same name,
+// such as rules.toml.disabled for rules.toml.
+const disabledMarkerExtension = ".disabled"
+
+// isDisabledMarker returns true if the path is the disabled marker of a configuration file.
+func isDisabledMarker(name string) bool {
+	return filepath.Ext(name) == disabledMarkerExtension && isConfigFile(strings.TrimSuffix(name, disabledMarkerExtension))
+}
+
+// hasDisabledMarker returns true if the configuration file is disabled by a marker file.
+func hasDisabledMarker(filename string) bool {
+	_, err := os.Stat(filename + disabledMarkerExtension)
+	return err == nil
+}
+
+// disabledConfiguration returns the empty configuration provided by a file disabled by its disabled key.
+func disabledConfiguration() *types.Configuration {
+	return &types.Configuration{
+		Frontends: make(map[string]*types.Frontend),
+		Backends:  make(map[string]*types.Backend),
+	}
+}
@@ -0,0 +1,84 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFileConfigFromDirectoryDisabled(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		content          string
+		marker           bool
+		expectedBackends []string
+	}{
+		{
+			desc:             "enabled file",
+			content:          backendWithURL("experimental", "http://172.17.0.2:80"),
+			expectedBackends: []string{"backend1", "experimental"},
+		},
+		{
+			desc:             "disabled key",
+			content:          "disabled = true\n" + backendWithURL("experimental", "http://172.17.0.2:80"),
+			expectedBackends: []string{"backend1"},
+		},
+		{
+			desc:             "disabled key set to false",
+			content:          "disabled = false\n" + backendWithURL("experimental", "http://172.17.0.2:80"),
+			expectedBackends: []string{"backend1", "experimental"},
+		},
+		{
+			desc:             "disabled marker file",
+			content:          backendWithURL("experimental", "http://172.17.0.2:80"),
+			marker:           true,
+			expectedBackends: []string{"backend1"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			createFile(t, tempDir, "backends.toml", createBackendConfiguration(1))
+			createFile(t, tempDir, "experimental.toml", test.content)
+			if test.marker {
+				createFile(t, tempDir, "experimental.toml.disabled", "")
+			}
+
+			pvd := &Provider{StrictValidation: true}
+			configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+			require.NoError(t, err)
+
+			backends := make(map[string]string)
+			for backendName := range configuration.Backends {
+				backends[backendName] = ""
+			}
+			assert.Equal(t, test.expectedBackends, sortedKeys(backends))
+		})
+	}
+}
+
+func TestIsDisabledMarker(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "rules.toml.disabled", expected: true},
+		{name: "rules.yml.tmpl.disabled", expected: true},
+		{name: "rules.toml"},
+		{name: "rules.disabled"},
+		{name: "notes.txt.disabled"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, isDisabledMarker(filepath.Join("conf", test.name)), test.name)
+	}
+}
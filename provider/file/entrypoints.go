@@ -0,0 +1,76 @@
+package file
+
+import (
+	"sort"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+)
+
+// withEntryPointFilter returns a copy of the configuration without the frontends none of whose entry points is in the EntryPointFilter,
+// nor the backends only used by these frontends, directly or by their error pages.
+// The backends which were not used by any frontend are kept.
+// The frontends and backends kept are shared with the configuration, whose maps are not modified,
+// so that the frontends filtered out are still there when the cached files are merged again on the next reload.
+func (p *Provider) withEntryPointFilter(configuration *types.Configuration) *types.Configuration {
+	if len(p.EntryPointFilter) == 0 {
+		return configuration
+	}
+
+	allowed := make(map[string]struct{}, len(p.EntryPointFilter))
+	for _, entryPoint := range p.EntryPointFilter {
+		allowed[entryPoint] = struct{}{}
+	}
+
+	filtered := &types.Configuration{
+		Frontends:        make(map[string]*types.Frontend, len(configuration.Frontends)),
+		Backends:         make(map[string]*types.Backend, len(configuration.Backends)),
+		TLSConfiguration: configuration.TLSConfiguration,
+	}
+
+	var droppedFrontends []string
+	kept := make(map[string]struct{})
+	dropped := make(map[string]struct{})
+	for frontendName, frontend := range configuration.Frontends {
+		references := kept
+		if hasEntryPoint(frontend, allowed) {
+			filtered.Frontends[frontendName] = frontend
+		} else {
+			droppedFrontends = append(droppedFrontends, frontendName)
+			references = dropped
+		}
+
+		references[frontend.Backend] = struct{}{}
+		for _, errorPage := range frontend.Errors {
+			references[errorPage.Backend] = struct{}{}
+		}
+	}
+
+	var droppedBackends []string
+	for backendName, backend := range configuration.Backends {
+		_, usedByKept := kept[backendName]
+		if _, usedByDropped := dropped[backendName]; usedByDropped && !usedByKept {
+			droppedBackends = append(droppedBackends, backendName)
+			continue
+		}
+		filtered.Backends[backendName] = backend
+	}
+
+	if len(droppedFrontends) > 0 {
+		sort.Strings(droppedFrontends)
+		sort.Strings(droppedBackends)
+		log.Infof("Filtered out the frontends %v, not using the entry points %v, and the backends %v they used",
+			droppedFrontends, []string(p.EntryPointFilter), droppedBackends)
+	}
+	return filtered
+}
+
+// hasEntryPoint returns true if one of the entry points of the frontend is allowed.
+func hasEntryPoint(frontend *types.Frontend, allowed map[string]struct{}) bool {
+	for _, entryPoint := range frontend.EntryPoints {
+		if _, ok := allowed[entryPoint]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package file
+
+import (
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEntryPointFilter(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		filter            StringList
+		expectedFrontends []string
+		expectedBackends  []string
+	}{
+		{
+			desc:              "no filter",
+			expectedFrontends: []string{"admin", "noEntryPoint", "public", "shared"},
+			expectedBackends:  []string{"admin", "errors", "public", "shared", "unused"},
+		},
+		{
+			desc:              "single entry point",
+			filter:            StringList{"http"},
+			expectedFrontends: []string{"public", "shared"},
+			expectedBackends:  []string{"public", "shared", "unused"},
+		},
+		{
+			desc:              "several entry points",
+			filter:            StringList{"http", "admin"},
+			expectedFrontends: []string{"admin", "public", "shared"},
+			expectedBackends:  []string{"admin", "errors", "public", "shared", "unused"},
+		},
+		{
+			desc:             "unknown entry point",
+			filter:           StringList{"other"},
+			expectedBackends: []string{"unused"},
+		},
+	}
+
+	configuration := &types.Configuration{
+		Frontends: map[string]*types.Frontend{
+			"public": {Backend: "public", EntryPoints: []string{"http", "https"}},
+			"shared": {Backend: "shared", EntryPoints: []string{"http"}},
+			"admin": {
+				Backend:     "admin",
+				EntryPoints: []string{"admin"},
+				Errors:      map[string]*types.ErrorPage{"5xx": {Backend: "errors", Status: []string{"500-599"}}},
+			},
+			"noEntryPoint": {Backend: "shared"},
+		},
+		Backends: map[string]*types.Backend{
+			"public": {},
+			"shared": {},
+			"admin":  {},
+			"errors": {},
+			"unused": {},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pvd := &Provider{EntryPointFilter: test.filter}
+			filtered := pvd.withEntryPointFilter(configuration)
+
+			assert.Equal(t, test.expectedFrontends, frontendNames(filtered))
+			assert.Equal(t, test.expectedBackends, backendNames(filtered))
+
+			// The filtered configuration is left unchanged
+			assert.Len(t, configuration.Frontends, 4)
+			assert.Len(t, configuration.Backends, 5)
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryEntryPointFilter(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", `
+[backends.backend1.servers.server1]
+url = "http://10.0.0.1:80"
+[backends.backend2.servers.server1]
+url = "http://10.0.0.2:80"
+`)
+	createFile(t, tempDir, "frontends.toml", `
+[frontends.frontend1]
+backend = "backend1"
+entryPoints = ["tenant1"]
+
+[frontends.frontend2]
+backend = "backend2"
+entryPoints = ["tenant2"]
+`)
+
+	pvd := &Provider{Directory: tempDir, EntryPointFilter: StringList{"tenant1"}}
+	configuration, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	assert.Len(t, configuration.Frontends, 1)
+	assert.NotNil(t, configuration.Frontends["frontend1"])
+	assert.Len(t, configuration.Backends, 1)
+	assert.NotNil(t, configuration.Backends["backend1"])
+}
+
+// frontendNames returns the sorted names of the frontends of the configuration, or nil without frontend.
+func frontendNames(configuration *types.Configuration) []string {
+	var names []string
+	for frontendName := range configuration.Frontends {
+		names = append(names, frontendName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// backendNames returns the sorted names of the backends of the configuration, or nil without backend.
+func backendNames(configuration *types.Configuration) []string {
+	var names []string
+	for backendName := range configuration.Backends {
+		names = append(names, backendName)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,109 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/log"
+)
+
+// resolvedPaths holds the configured paths of the configuration files with their environment variable references expanded.
+type resolvedPaths struct {
+	filename          string
+	directory         string
+	directories       []string
+	overrideDirectory string
+	files             []string
+}
+
+// resolvePaths expands the environment variable references of the configured paths, such as ${CONFIG_DIR}/dynamic,
+// so that the resolved paths are loaded and watched, the configured ones staying unchanged.
+// It returns an error if a referenced variable is not set, rather than loading and watching a path such as /dynamic.
+// It is called by the entry points loading the configuration: Provide, BuildConfiguration and CheckConfiguration.
+func (p *Provider) resolvePaths() error {
+	filename, err := expandPath("filename", p.Filename)
+	if err != nil {
+		return err
+	}
+	directory, err := expandPath("directory", p.Directory)
+	if err != nil {
+		return err
+	}
+	directories, err := expandPathList("directory", p.Directories)
+	if err != nil {
+		return err
+	}
+	overrideDirectory, err := expandPath("override directory", p.OverrideDirectory)
+	if err != nil {
+		return err
+	}
+	files, err := expandPathList("file", p.Files)
+	if err != nil {
+		return err
+	}
+
+	p.paths.Set(&resolvedPaths{
+		filename:          filename,
+		directory:         directory,
+		directories:       directories,
+		overrideDirectory: overrideDirectory,
+		files:             files,
+	})
+	return nil
+}
+
+// resolved returns the paths resolved by resolvePaths, or the configured ones if they were not resolved yet.
+func (p *Provider) resolved() *resolvedPaths {
+	if paths, ok := p.paths.Get().(*resolvedPaths); ok {
+		return paths
+	}
+	return &resolvedPaths{
+		filename:          p.Filename,
+		directory:         p.Directory,
+		directories:       p.Directories,
+		overrideDirectory: p.OverrideDirectory,
+		files:             p.Files,
+	}
+}
+
+// expandPathList returns the paths with their environment variable references expanded.
+func expandPathList(kind string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	expanded := make([]string, 0, len(paths))
+	for _, path := range paths {
+		expandedPath, err := expandPath(kind, path)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, expandedPath)
+	}
+	return expanded, nil
+}
+
+// expandPath returns the path with its environment variable references expanded, logging the resolved path if it differs.
+// It returns an error naming the referenced variables which are not set.
+func expandPath(kind, path string) (string, error) {
+	var unset []string
+	expanded := os.Expand(path, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			unset = append(unset, name)
+		}
+		return value
+	})
+	if len(unset) > 0 {
+		sort.Strings(unset)
+		return "", fmt.Errorf("environment variables %s referenced by the %s %s are not set", strings.Join(unset, ", "), kind, path)
+	}
+	if expanded == path {
+		return path, nil
+	}
+
+	log.Infof("Resolved the %s %s to %s", kind, path, expanded)
+	return expanded, nil
+}
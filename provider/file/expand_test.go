@@ -0,0 +1,189 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/provider"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandPath(t *testing.T) {
+	os.Setenv("TRAEFIK_TEST_CONFIG_DIR", "/etc/traefik")
+	defer os.Unsetenv("TRAEFIK_TEST_CONFIG_DIR")
+	os.Setenv("TRAEFIK_TEST_ENVIRONMENT", "staging")
+	defer os.Unsetenv("TRAEFIK_TEST_ENVIRONMENT")
+
+	testCases := []struct {
+		desc          string
+		path          string
+		expected      string
+		expectedError string
+	}{
+		{
+			desc:     "no reference",
+			path:     "/etc/traefik/dynamic",
+			expected: "/etc/traefik/dynamic",
+		},
+		{
+			desc:     "braced reference",
+			path:     "${TRAEFIK_TEST_CONFIG_DIR}/dynamic",
+			expected: "/etc/traefik/dynamic",
+		},
+		{
+			desc:     "several references",
+			path:     "$TRAEFIK_TEST_CONFIG_DIR/${TRAEFIK_TEST_ENVIRONMENT}/rules.toml",
+			expected: "/etc/traefik/staging/rules.toml",
+		},
+		{
+			desc:          "unset variable",
+			path:          "${TRAEFIK_TEST_UNSET}/dynamic",
+			expectedError: "environment variables TRAEFIK_TEST_UNSET referenced by the directory ${TRAEFIK_TEST_UNSET}/dynamic are not set",
+		},
+		{
+			desc:          "several unset variables",
+			path:          "$TRAEFIK_TEST_UNSET_ROOT/${TRAEFIK_TEST_CONFIG_DIR}/$TRAEFIK_TEST_UNSET",
+			expectedError: "environment variables TRAEFIK_TEST_UNSET, TRAEFIK_TEST_UNSET_ROOT referenced by the directory $TRAEFIK_TEST_UNSET_ROOT/${TRAEFIK_TEST_CONFIG_DIR}/$TRAEFIK_TEST_UNSET are not set",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			expanded, err := expandPath("directory", test.path)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, expanded)
+		})
+	}
+}
+
+func TestBuildConfigurationUnsetPathVariable(t *testing.T) {
+	pvd := &Provider{
+		Directory:    "${TRAEFIK_TEST_UNSET}/dynamic",
+		ProviderName: "file",
+	}
+	pvd.Watch = true
+
+	_, err := pvd.BuildConfiguration()
+	assert.EqualError(t, err, "environment variables TRAEFIK_TEST_UNSET referenced by the directory ${TRAEFIK_TEST_UNSET}/dynamic are not set")
+
+	assert.Error(t, pvd.CheckConfiguration())
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	// The provider does not start rather than loading and watching /dynamic
+	configurationChan := make(chan types.ConfigMessage, 10)
+	assert.Error(t, pvd.Provide(configurationChan, pool, nil))
+	assert.Len(t, configurationChan, 0)
+}
+
+func TestProvideExpandedDirectory(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	dynamicDir := createSubDir(t, tempDir, "dynamic")
+	createFile(t, dynamicDir, "backends.toml", createBackendConfiguration(2))
+
+	os.Setenv("TRAEFIK_TEST_CONFIG_DIR", tempDir)
+	defer os.Unsetenv("TRAEFIK_TEST_CONFIG_DIR")
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{
+		Directory:    filepath.Join("${TRAEFIK_TEST_CONFIG_DIR}", "dynamic"),
+		ProviderName: "file",
+	}
+	pvd.Watch = true
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	require.NoError(t, pvd.Provide(configurationChan, pool, nil))
+	assert.Len(t, receiveConfiguration(t, configurationChan).Backends, 2)
+	// The configured directory is left unchanged
+	assert.Equal(t, filepath.Join("${TRAEFIK_TEST_CONFIG_DIR}", "dynamic"), pvd.Directory)
+
+	directories, err := pvd.watchedDirectories()
+	require.NoError(t, err)
+	assert.Equal(t, []string{dynamicDir}, directories)
+}
+
+func TestBuildConfigurationExpandedPaths(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	firstDir := createSubDir(t, tempDir, "first")
+	createFile(t, firstDir, "backends.toml", backendWithURL("backend1", "http://172.17.0.1:80"))
+	secondDir := createSubDir(t, tempDir, "second")
+	createFile(t, secondDir, "backends.toml", backendWithURL("backend2", "http://172.17.0.2:80"))
+	overrideDir := createSubDir(t, tempDir, "override")
+	createFile(t, overrideDir, "backends.toml", backendWithURL("backend1", "http://172.17.0.3:80"))
+
+	os.Setenv("TRAEFIK_TEST_CONFIG_DIR", tempDir)
+	defer os.Unsetenv("TRAEFIK_TEST_CONFIG_DIR")
+
+	testCases := []struct {
+		desc     string
+		provider func() *Provider
+		expected map[string]string
+	}{
+		{
+			desc: "filename",
+			provider: func() *Provider {
+				return &Provider{BaseProvider: provider.BaseProvider{Filename: "${TRAEFIK_TEST_CONFIG_DIR}/first/backends.toml"}}
+			},
+			expected: map[string]string{"backend1": "http://172.17.0.1:80"},
+		},
+		{
+			desc: "directories and override directory",
+			provider: func() *Provider {
+				return &Provider{
+					Directories:       StringList{"${TRAEFIK_TEST_CONFIG_DIR}/first", "$TRAEFIK_TEST_CONFIG_DIR/second"},
+					OverrideDirectory: "${TRAEFIK_TEST_CONFIG_DIR}/override",
+				}
+			},
+			expected: map[string]string{"backend1": "http://172.17.0.3:80", "backend2": "http://172.17.0.2:80"},
+		},
+		{
+			desc: "files",
+			provider: func() *Provider {
+				return &Provider{Files: StringList{"${TRAEFIK_TEST_CONFIG_DIR}/first/backends.toml", "${TRAEFIK_TEST_CONFIG_DIR}/second/backends.toml"}}
+			},
+			expected: map[string]string{"backend1": "http://172.17.0.1:80", "backend2": "http://172.17.0.2:80"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			pvd := test.provider()
+			configured := test.provider()
+
+			configuration, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			urls := make(map[string]string)
+			for name, backend := range configuration.Backends {
+				urls[name] = backend.Servers["server1"].URL
+			}
+			assert.Equal(t, test.expected, urls)
+
+			assert.NoError(t, pvd.CheckConfiguration())
+
+			// The configured paths are left unchanged
+			assert.Equal(t, configured.Filename, pvd.Filename)
+			assert.Equal(t, configured.Directories, pvd.Directories)
+			assert.Equal(t, configured.OverrideDirectory, pvd.OverrideDirectory)
+			assert.Equal(t, configured.Files, pvd.Files)
+		})
+	}
+}
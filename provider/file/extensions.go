@@ -0,0 +1,64 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+)
+
+// extensions returns the extensions of the files to load, normalized, or all the supported ones without Extensions.
+func (p *Provider) extensions() []string {
+	if len(p.Extensions) == 0 {
+		return configExtensions
+	}
+
+	extensions := make([]string, 0, len(p.Extensions))
+	for _, extension := range p.Extensions {
+		extensions = append(extensions, normalizeExtension(extension))
+	}
+	return extensions
+}
+
+// normalizeExtension returns the extension in lower case, with its leading dot added if missing, such as ".yaml" for "YAML".
+func normalizeExtension(extension string) string {
+	extension = strings.ToLower(strings.TrimSpace(extension))
+	if !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	return extension
+}
+
+// checkExtensions returns an error if one of the Extensions is not a supported one.
+func (p *Provider) checkExtensions() error {
+	for _, extension := range p.Extensions {
+		if !isSupportedExtension(normalizeExtension(extension)) {
+			return fmt.Errorf("unsupported extension %q, the supported extensions are: %s", extension, strings.Join(configExtensions, ", "))
+		}
+	}
+	return nil
+}
+
+func isSupportedExtension(extension string) bool {
+	for _, supported := range configExtensions {
+		if extension == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSelectedExtension returns true if the file is a configuration file with one of the extensions to load,
+// ignoring its compression extension, and its template extension unless the template extension is selected:
+// ".yaml" selects rules.yaml, rules.yaml.gz and rules.yaml.tmpl, while ".tmpl" selects all the templates.
+func (p *Provider) hasSelectedExtension(filename string) bool {
+	if !isConfigFile(filename) {
+		return false
+	}
+
+	name := strings.ToLower(uncompressedName(filename))
+	for _, extension := range p.extensions() {
+		if strings.HasSuffix(name, extension) || strings.HasSuffix(strings.TrimSuffix(name, templateExtension), extension) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/fsnotify.v1"
+)
+
+func TestHasSelectedExtension(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		extensions StringList
+		filename   string
+		expected   bool
+	}{
+		{desc: "default extensions, TOML file", filename: "/etc/traefik/rules.toml", expected: true},
+		{desc: "default extensions, template", filename: "/etc/traefik/rules.tmpl", expected: true},
+		{desc: "default extensions, unsupported extension", filename: "/etc/traefik/rules.txt", expected: false},
+		{desc: "selected extension", extensions: StringList{".yaml"}, filename: "/etc/traefik/rules.yaml", expected: true},
+		{desc: "selected extension in upper case", extensions: StringList{".yaml"}, filename: "/etc/traefik/RULES.YAML", expected: true},
+		{desc: "other extension", extensions: StringList{".yaml"}, filename: "/etc/traefik/rules.toml", expected: false},
+		{desc: "similar extension", extensions: StringList{".yaml"}, filename: "/etc/traefik/rules.yml", expected: false},
+		{desc: "compressed file", extensions: StringList{".yaml"}, filename: "/etc/traefik/rules.yaml.gz", expected: true},
+		{desc: "template of the selected extension", extensions: StringList{".yaml"}, filename: "/etc/traefik/rules.yaml.tmpl", expected: true},
+		{desc: "template of another extension", extensions: StringList{".yaml"}, filename: "/etc/traefik/rules.toml.tmpl", expected: false},
+		{desc: "selected templates", extensions: StringList{".tmpl"}, filename: "/etc/traefik/rules.toml.tmpl", expected: true},
+		{desc: "extension without dot", extensions: StringList{"json"}, filename: "/etc/traefik/rules.json", expected: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pvd := &Provider{Extensions: test.extensions}
+			assert.Equal(t, test.expected, pvd.hasSelectedExtension(test.filename))
+		})
+	}
+}
+
+func TestCheckExtensions(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		extensions    StringList
+		expectedError string
+	}{
+		{
+			desc: "default extensions",
+		},
+		{
+			desc:       "supported extensions",
+			extensions: StringList{".yaml", "YML", " .json"},
+		},
+		{
+			desc:          "unsupported extension",
+			extensions:    StringList{".yaml", "conf"},
+			expectedError: `unsupported extension "conf", the supported extensions are: .toml, .tml, .yml, .yaml, .json, .tmpl`,
+		},
+		{
+			desc:          "compression extension",
+			extensions:    StringList{".gz"},
+			expectedError: `unsupported extension ".gz"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Provider{Extensions: test.extensions}).checkExtensions()
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedError)
+		})
+	}
+}
+
+func TestBuildConfigurationExtensions(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+	createFile(t, tempDir, "frontends.yaml", createYAMLFrontendConfiguration(2))
+	createFile(t, tempDir, "backends.yaml", createYAMLBackendConfiguration(2))
+
+	pvd := &Provider{Directory: tempDir, Extensions: StringList{"yaml"}}
+	configuration, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+	assert.Len(t, configuration.Frontends, 2)
+	assert.Len(t, configuration.Backends, 2)
+	assert.Equal(t, []string{filepath.Join(tempDir, "backends.yaml"), filepath.Join(tempDir, "frontends.yaml")}, pvd.LoadedFiles())
+
+	// The changes of the files with other extensions are not watched
+	assert.True(t, pvd.isWatchedEvent(fsnotify.Event{Name: filepath.Join(tempDir, "backends.yaml"), Op: fsnotify.Write}))
+	assert.False(t, pvd.isWatchedEvent(fsnotify.Event{Name: filepath.Join(tempDir, "backends.toml"), Op: fsnotify.Write}))
+
+	pvd.Extensions = StringList{"yaml", "ini"}
+	_, err = pvd.BuildConfiguration()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported extension "ini"`)
+}
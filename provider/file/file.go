@@ -1,18 +1,28 @@
 package file
 
 import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/BurntSushi/toml"
+	"github.com/cenk/backoff"
+	"github.com/containous/flaeg"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/provider"
 	"github.com/containous/traefik/safe"
-	"github.com/containous/traefik/tls"
 	"github.com/containous/traefik/types"
 	"gopkg.in/fsnotify.v1"
 )
@@ -21,182 +31,1636 @@ var _ provider.Provider = (*Provider)(nil)
 
 // Provider holds configurations of the provider.
 type Provider struct {
-	provider.BaseProvider `mapstructure:",squash" export:"true"`
-	Directory             string `description:"Load configuration from one or more .toml files in a directory" export:"true"`
+	provider.BaseProvider   `mapstructure:",squash" export:"true"`
+	Directory               string           `description:"Load configuration from one or more .toml, .yml or .json files in a directory" export:"true"`
+	Directories             StringList       `description:"Load configuration from the files of several directories, in order, after the directory" export:"true"`
+	OverrideDirectory       string           `description:"Load configuration from the files of a directory after the other ones, replacing their definitions" export:"true"`
+	MergeFilename           bool             `description:"Load the filename after the directories, merged with them, instead of ignoring it" export:"true"`
+	Files                   StringList       `description:"Load configuration from a list of files, in order" export:"true"`
+	Archive                 string           `description:"Load configuration from the .toml, .yml or .json files of a tar archive, optionally compressed with gzip" export:"true"`
+	MergeStrategy           string           `description:"Strategy for frontends and backends defined in several files: skip, replace, merge or mergeServers" export:"true"`
+	NamespaceByFile         bool             `description:"Prefix the names of the frontends and backends with the path of the file defining them" export:"true"`
+	FilePattern             string           `description:"Only load the files of the directory whose name matches this glob pattern" export:"true"`
+	Extensions              StringList       `description:"Only load the files of the directory with these extensions, among .toml, .tml, .yml, .yaml, .json and .tmpl" export:"true"`
+	DebounceDuration        flaeg.Duration   `description:"Wait for this duration without file changes before reloading the configuration" export:"true"`
+	MinReloadInterval       flaeg.Duration   `description:"Minimum duration between two reloads triggered by file changes" export:"true"`
+	StartupDelay            flaeg.Duration   `description:"Wait for this duration after the start before loading the configuration, the files being watched meanwhile" export:"true"`
+	ReloadRetries           int              `description:"Number of times a configuration which fails to load after a change is loaded again" export:"true"`
+	ReloadRetryDelay        flaeg.Duration   `description:"Delay before loading again a configuration which failed to load after a change, doubled on each retry" export:"true"`
+	FollowSymlinks          bool             `description:"Load and watch the directories targeted by symbolic links" export:"true"`
+	SkipInvalidFiles        bool             `description:"Skip the files of the directory which can not be loaded instead of failing" export:"true"`
+	MaxFileSize             int64            `description:"Maximum size in bytes of the configuration files, the larger files of the directory being skipped (0 for unlimited)" export:"true"`
+	RequireAtLeastOneFile   bool             `description:"Fail the loading of a directory without any configuration file matching the supported extensions" export:"true"`
+	StrictValidation        bool             `description:"Reject inconsistent configurations instead of logging warnings" export:"true"`
+	WarningsAsErrors        bool             `description:"Fail the loading of configurations defining frontends, backends, servers or TLS configurations several times instead of logging warnings" export:"true"`
+	AllowEmptyConfiguration bool             `description:"Accept configurations without any frontend, backend or TLS configuration" export:"true"`
+	WatcherRestartMinDelay  flaeg.Duration   `description:"Initial delay before restarting a failed file watcher" export:"true"`
+	WatcherRestartMaxDelay  flaeg.Duration   `description:"Maximum delay between the attempts to restart a failed file watcher" export:"true"`
+	ProviderName            string           `description:"Name of the provider in the configurations it sends" export:"true"`
+	CertExpiryWarning       flaeg.Duration   `description:"Warn about the certificates expiring within this duration" export:"true"`
+	MaxDepth                int              `description:"Maximum depth of the loaded and watched directories, the directory being at depth 1 (0 for unlimited)" export:"true"`
+	LoadConcurrency         int              `description:"Maximum number of files of the directory parsed concurrently (0 for the number of CPUs used)" export:"true"`
+	IncludeHiddenFiles      bool             `description:"Load the files of the directory starting with a dot or ending with a tilde" export:"true"`
+	TemplateValuesFile      string           `description:"TOML, YAML or JSON file of the values rendered by the templates" export:"true"`
+	DumpRenderedTemplates   bool             `description:"Write the rendered text of the templates which can not be decoded to a temporary file" export:"true"`
+	SchemaFile              string           `description:"JSON Schema file against which each configuration file is validated" export:"true"`
+	DefaultsFile            string           `description:"TOML, YAML or JSON file of the default settings of the backends and frontends which do not set them" export:"true"`
+	RemoteURL               string           `description:"Load configuration from an HTTP(S) URL instead of a file" export:"true"`
+	RemotePollInterval      flaeg.Duration   `description:"Interval between the checks for changes of the remote configuration" export:"true"`
+	LogConfigDiff           bool             `description:"Log the frontends and backends added, removed or modified by each reload" export:"true"`
+	LenientDecode           bool             `description:"Ignore the unknown top-level keys of the configuration files instead of failing" export:"true"`
+	DirectWatch             bool             `description:"Watch the configuration file itself instead of its directory" export:"true"`
+	PollInterval            flaeg.Duration   `description:"Interval between the checks for changes of the configuration files when they are polled, such as when they can not be watched" export:"true"`
+	ForcePoll               bool             `description:"Poll the configuration files for changes instead of watching them, for the file systems without reliable notifications" export:"true"`
+	TriggerFile             string           `description:"Only reload the configuration when this file changes, ignoring the changes of the configuration files" export:"true"`
+	ActiveProfile           string           `description:"Profile whose definitions of the configuration files are overlaid on the base ones" export:"true"`
+	ValidateRules           bool             `description:"Parse the routing rules of the frontends when loading the configuration, reporting the invalid ones as inconsistencies" export:"true"`
+	EntryPointFilter        StringList       `description:"Only load the frontends using one of these entry points, along with the backends they use" export:"true"`
+	MetricsRegistry         metrics.Registry `json:"-"`
+	// StaticConfiguration, if set, is merged with the configuration files as if loaded before them,
+	// or sent alone if no configuration file is configured
+	StaticConfiguration *types.Configuration `json:"-"`
+	// OnConfiguration, if set, is called with each configuration right before it is sent, and may modify it
+	OnConfiguration func(*types.Configuration) `json:"-"`
+	// Decryptor, if set, decrypts the encrypted configuration files before they are rendered and decoded:
+	// the files whose name ends with .enc before their extension, such as rules.enc.toml, and the files with a SOPS envelope
+	Decryptor func([]byte) ([]byte, error) `json:"-"`
+	// Transform, if set, rewrites each loaded configuration before it is verified and sent, initially and on each reload.
+	// It must not modify the frontends and backends of the configuration given, which may be cached, but may replace them.
+	// An error fails the loading, the last configuration sent staying in use
+	Transform func(*types.Configuration) (*types.Configuration, error) `json:"-"`
+	// RuleValidator parses a routing rule as the server does, to report the invalid rules with ValidateRules
+	RuleValidator func(rule string) error `json:"-"`
+	// cache holds the *fileCache of the last loading of the directory
+	cache safe.Safe
+	// lastConfiguration holds the last configuration sent, which stays in use when a reload fails
+	lastConfiguration safe.Safe
+	// lastConfigurationHash holds the hash of the last configuration sent, to skip sending it again unchanged
+	lastConfigurationHash safe.Safe
+	// configurationChan holds the channel given to Provide, on which ForceReload sends the configuration
+	configurationChan safe.Safe
+	// reloadLock serializes the reloads, triggered by the watcher, the polling of the RemoteURL, Reload or ForceReload
+	reloadLock sync.Mutex
+	// staleReloads counts the reloads failed since the last configuration sent
+	staleReloads int32
+	// firstLoadPending is 1 while the first configuration waits for the StartupDelay, the reloads being skipped meanwhile
+	firstLoadPending int32
+	// ignorePatterns holds the patterns of the ignore files, by configured directory
+	ignorePatterns safe.Safe
+	// templateValues holds the values of the TemplateValuesFile
+	templateValues safe.Safe
+	// schema holds the *gojsonschema.Schema of the SchemaFile
+	schema safe.Safe
+	// defaults holds the *fileDefaults of the DefaultsFile
+	defaults safe.Safe
+	// remoteContent holds the *remoteContent last fetched from the RemoteURL
+	remoteContent safe.Safe
+	// loadStats holds the *loadStats of the last configuration loaded
+	loadStats safe.Safe
+	// origins holds the *configurationOrigins of the last configuration loaded
+	origins safe.Safe
+	// paths holds the *resolvedPaths of the configured paths
+	paths safe.Safe
+	// readDir lists the content of a directory instead of ioutil.ReadDir if set, in tests
+	readDir func(dirname string) ([]os.FileInfo, error)
+}
+
+// stdinFilename is the filename reading the configuration from the standard input.
+const stdinFilename = "-"
+
+// stdin is the standard input, replaced in tests.
+var stdin io.Reader = os.Stdin
+
+// Triggers of the configurations sent by the provider.
+const (
+	triggerInitial = "initial"
+	triggerWatch   = "watch"
+	triggerReload  = "reload"
+	triggerForce   = "force"
+)
+
+// loadState holds the state of the loading of a directory tree.
+type loadState struct {
+	// visited holds the directories already loaded, to prevent loops through symbolic links.
+	visited map[string]struct{}
+	// invalidFiles holds the files skipped because they could not be loaded.
+	invalidFiles []string
+	// files holds the files to load, in load order.
+	files []string
+}
+
+// providerName returns the ProviderName, or "file" if it is empty.
+func (p *Provider) providerName() string {
+	if p.ProviderName == "" {
+		return "file"
+	}
+	return p.ProviderName
 }
 
 // Provide allows the file provider to provide configurations to traefik
 // using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- types.ConfigMessage, pool *safe.Pool, constraints types.Constraints) error {
-	configuration, err := p.BuildConfiguration()
+	log.Infof("Providing the file configurations as provider %q", p.providerName())
+	p.configurationChan.Set(configurationChan)
+	if err := p.resolvePaths(); err != nil {
+		return err
+	}
+	if filename := p.resolved().filename; p.hasDirectories() && filename != "" && !p.MergeFilename {
+		log.Warnf("Ignoring the filename %s since the configuration is loaded from directories, enable mergeFilename to load it as well", filename)
+	}
 
+	if p.StartupDelay > 0 {
+		return p.provideAfterStartupDelay(configurationChan, pool)
+	}
+
+	start := time.Now()
+	configuration, err := p.buildInitialConfiguration()
 	if err != nil {
 		return err
 	}
+	p.observeLoadDuration(configuration, triggerInitial, time.Since(start))
+
+	if err := p.watch(configurationChan, pool); err != nil {
+		return err
+	}
 
-	if p.Watch {
-		var watchItem string
+	p.sendConfigToChannel(configurationChan, configuration, triggerInitial)
+	return nil
+}
 
-		if p.Directory != "" {
-			watchItem = p.Directory
-		} else {
-			watchItem = filepath.Dir(p.Filename)
+// watch starts watching the configuration files, or polling the RemoteURL, if Watch is enabled.
+// The configuration files are polled instead with ForcePoll, or when they can not be watched and a PollInterval is set.
+func (p *Provider) watch(configurationChan chan<- types.ConfigMessage, pool *safe.Pool) error {
+	if p.Watch && p.readsStdin() {
+		log.Warn("The configuration read from stdin can not be watched, ignoring watch")
+	} else if p.Watch && p.readsStaticOnly() {
+		log.Debug("No configuration file to watch along with the static configuration, ignoring watch")
+	} else if p.Watch && p.readsRemote() {
+		if err := p.pollRemote(pool, configurationChan); err != nil {
+			return err
 		}
-
-		if err := p.addWatcher(pool, watchItem, configurationChan, p.watcherCallback); err != nil {
+	} else if p.Watch && p.ForcePoll {
+		return p.pollFiles(pool, configurationChan)
+	} else if p.Watch {
+		watchItems, err := p.watchedDirectories()
+		if err != nil {
 			return err
 		}
-	}
 
-	sendConfigToChannel(configurationChan, configuration)
+		if err := p.addWatcher(pool, watchItems, configurationChan, p.watcherCallback); err != nil {
+			if p.PollInterval <= 0 {
+				return err
+			}
+			log.Warnf("%v, polling the configuration files every %s instead", err, time.Duration(p.PollInterval))
+			return p.pollFiles(pool, configurationChan)
+		}
+	}
 	return nil
 }
 
+// buildInitialConfiguration builds the configuration sent when the provider starts.
+// A watched file which does not exist yet provides an empty configuration until it is created.
+func (p *Provider) buildInitialConfiguration() (*types.Configuration, error) {
+	if p.Watch && p.readsSingleFile() && !p.readsStdin() && !p.readsStaticOnly() {
+		filename := p.resolved().filename
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			log.Warnf("Configuration file %s does not exist, waiting for its creation", filename)
+
+			configuration := &types.Configuration{
+				Frontends: make(map[string]*types.Frontend),
+				Backends:  make(map[string]*types.Backend),
+			}
+			return p.finalizeConfiguration(configuration)
+		}
+	}
+	return p.buildConfiguration()
+}
+
 // BuildConfiguration loads configuration either from file or a directory specified by 'Filename'/'Directory'
 // and returns a 'Configuration' object
 func (p *Provider) BuildConfiguration() (*types.Configuration, error) {
-	if p.Directory != "" {
-		return loadFileConfigFromDirectory(p.Directory, nil)
+	if err := p.resolvePaths(); err != nil {
+		return nil, err
 	}
-	return loadFileConfig(p.Filename)
+	return p.buildConfiguration()
 }
 
-func (p *Provider) addWatcher(pool *safe.Pool, directory string, configurationChan chan<- types.ConfigMessage, callback func(chan<- types.ConfigMessage, fsnotify.Event)) error {
-	watcher, err := fsnotify.NewWatcher()
+// buildConfiguration loads the configuration from the resolved paths, then finalizes it.
+func (p *Provider) buildConfiguration() (*types.Configuration, error) {
+	configuration, err := p.loadConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	return p.finalizeConfiguration(configuration)
+}
+
+// finalizeConfiguration applies the Transform to the loaded configuration, then verifies the configuration it returns.
+func (p *Provider) finalizeConfiguration(configuration *types.Configuration) (*types.Configuration, error) {
+	if p.Transform != nil {
+		transformed, err := p.Transform(configuration)
+		if err != nil {
+			return nil, fmt.Errorf("unable to transform the configuration loaded from %s: %v", p.configurationSource(), err)
+		}
+		if transformed == nil {
+			return nil, fmt.Errorf("unable to transform the configuration loaded from %s: no configuration returned", p.configurationSource())
+		}
+		configuration = transformed
+	}
+
+	if err := p.verifyConfiguration(configuration); err != nil {
+		return nil, err
+	}
+	return configuration, nil
+}
+
+// verifyConfiguration rejects the empty configuration if not allowed, then validates the configuration.
+func (p *Provider) verifyConfiguration(configuration *types.Configuration) error {
+	if !p.AllowEmptyConfiguration && isEmptyConfiguration(configuration) {
+		return fmt.Errorf("empty configuration loaded from %s", p.configurationSource())
+	}
+
+	p.checkCertificatesExpiry(configuration)
+	return p.validateConfiguration(configuration)
+}
+
+// readsStdin returns true if the configuration is read from the standard input.
+func (p *Provider) readsStdin() bool {
+	return p.readsSingleFile() && p.resolved().filename == stdinFilename
+}
+
+// configurationSource describes where the configuration is loaded from.
+func (p *Provider) configurationSource() string {
+	if directories := p.directories(); len(directories) > 0 {
+		source := fmt.Sprintf("directory %q", directories[0])
+		if len(directories) > 1 {
+			source = fmt.Sprintf("directories %q", directories)
+		}
+		if p.mergesFilename() {
+			source += fmt.Sprintf(" and file %q", p.resolved().filename)
+		}
+		return source
+	}
+	if p.hasFiles() {
+		return fmt.Sprintf("files %q", p.resolved().files)
+	}
+	if p.readsArchive() {
+		return fmt.Sprintf("archive %q", p.Archive)
+	}
+	if p.readsRemote() {
+		return fmt.Sprintf("URL %q", p.RemoteURL)
+	}
+	if p.readsStdin() {
+		return "stdin"
+	}
+	if p.readsStaticOnly() {
+		return staticConfigurationOrigin
+	}
+	return fmt.Sprintf("file %q", p.resolved().filename)
+}
+
+func isEmptyConfiguration(configuration *types.Configuration) bool {
+	return len(configuration.Frontends) == 0 && len(configuration.Backends) == 0 && len(configuration.TLSConfiguration) == 0
+}
+
+func (p *Provider) loadConfiguration() (*types.Configuration, error) {
+	configuration, err := p.loadSources()
+	if err != nil {
+		return nil, err
+	}
+	return p.withStaticConfiguration(p.withEntryPointFilter(p.withDefaults(configuration)))
+}
+
+// loadSources loads the configuration of the directories, files or URL.
+func (p *Provider) loadSources() (*types.Configuration, error) {
+	if err := p.checkExtensions(); err != nil {
+		return nil, err
+	}
+	if err := p.loadTemplateValues(); err != nil {
+		return nil, err
+	}
+	if err := p.loadSchema(); err != nil {
+		return nil, err
+	}
+	if err := p.loadDefaults(); err != nil {
+		return nil, err
+	}
+
+	if p.readsStaticOnly() {
+		configuration := &types.Configuration{
+			Frontends: make(map[string]*types.Frontend),
+			Backends:  make(map[string]*types.Backend),
+		}
+		p.loadStats.Set(&loadStats{})
+		p.setOrigins(newConfigurationOrigins("", configuration))
+		return configuration, nil
+	}
+
+	if p.hasDirectories() {
+		if _, err := filepath.Match(p.FilePattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %v", p.FilePattern, err)
+		}
+
+		cache, err := p.loadDirectories(p.directories())
+		if err != nil {
+			return nil, err
+		}
+		if p.mergesFilename() {
+			if err := p.addFilename(cache); err != nil {
+				return nil, err
+			}
+		}
+		p.cache.Set(cache)
+		p.loadStats.Set(cache.stats())
+		return p.mergeFileCache(cache)
+	}
+	if p.hasFiles() {
+		cache, err := p.loadFiles()
+		if err != nil {
+			return nil, err
+		}
+		p.cache.Set(cache)
+		p.loadStats.Set(cache.stats())
+		return p.mergeFileCache(cache)
+	}
+	if p.readsArchive() {
+		cache, err := p.loadArchive()
+		if err != nil {
+			return nil, err
+		}
+		p.loadStats.Set(cache.stats())
+		return p.mergeFileCache(cache)
+	}
+	if p.readsRemote() {
+		p.loadStats.Set(&loadStats{})
+		return p.loadRemoteConfiguration()
+	}
+
+	entry, err := p.loadCachedFile(p.resolved().filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := entry.warnings.err(); err != nil {
+		return nil, err
+	}
+	p.loadStats.Set(&loadStats{files: sortedFiles(entry.dependencies), lastModified: entry.modTime})
+	p.setOrigins(entry.origins)
+	return entry.configuration, nil
+}
+
+// addWatcher watches all the directories with a single watcher, the directories of the configuration along with the ones of
+// the TemplateValuesFile and DefaultsFile, so that changes of several of them are debounced together into a single reload.
+func (p *Provider) addWatcher(pool *safe.Pool, directories []string, configurationChan chan<- types.ConfigMessage, callback func(chan<- types.ConfigMessage, fsnotify.Event, chan bool)) error {
+	watcher, err := newWatcher(directories)
 	if err != nil {
-		return fmt.Errorf("error creating file watcher: %s", err)
+		return err
 	}
 
-	// Process events
+	roots := watchedRoots(directories)
 	pool.Go(func(stop chan bool) {
-		defer watcher.Close()
 		for {
-			select {
-			case <-stop:
+			if !p.processEvents(watcher, roots, stop, configurationChan, callback) {
 				return
-			case evt := <-watcher.Events:
-				if p.Directory == "" {
-					_, evtFileName := filepath.Split(evt.Name)
-					_, confFileName := filepath.Split(p.Filename)
-					if evtFileName == confFileName {
-						callback(configurationChan, evt)
-					}
-				} else {
-					callback(configurationChan, evt)
-				}
-			case err := <-watcher.Errors:
-				log.Errorf("Watcher event error: %s", err)
 			}
+
+			watcher, roots = p.restartWatcher(stop)
+			if watcher == nil {
+				return
+			}
+
+			// Changes may have been missed while the watcher was down
+			callback(configurationChan, fsnotify.Event{}, stop)
 		}
 	})
-	err = watcher.Add(directory)
-	if err != nil {
-		return fmt.Errorf("error adding file watcher: %s", err)
-	}
 
 	return nil
 }
 
-func (p *Provider) watcherCallback(configurationChan chan<- types.ConfigMessage, event fsnotify.Event) {
-	watchItem := p.Filename
-	if p.Directory != "" {
-		watchItem = p.Directory
+// newWatcher creates a watcher on the directories.
+func newWatcher(directories []string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating file watcher: %s", err)
 	}
 
-	if _, err := os.Stat(watchItem); err != nil {
-		log.Debugf("Unable to watch %s : %v", watchItem, err)
-		return
+	for _, directory := range directories {
+		if err := watcher.Add(directory); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("error adding file watcher: %s", err)
+		}
 	}
+	return watcher, nil
+}
 
-	configuration, err := p.BuildConfiguration()
+// maxWatcherErrors is the number of errors in a row, without any event between them, after which the watcher is considered failed:
+// the watcher keeps running when it fails to read its events, only reporting the failure as an error.
+const maxWatcherErrors = 3
 
-	if err != nil {
-		log.Errorf("Error occurred during watcher callback: %s", err)
-		return
+// processEvents calls the callback on the events of the watcher, until the provider is stopped or the watcher fails.
+// It returns true if the watcher failed: its channels were closed, it reported maxWatcherErrors errors in a row,
+// or one of its roots, the directories returned by watchedRoots, was removed.
+func (p *Provider) processEvents(watcher *fsnotify.Watcher, roots []string, stop chan bool, configurationChan chan<- types.ConfigMessage, callback func(chan<- types.ConfigMessage, fsnotify.Event, chan bool)) bool {
+	defer watcher.Close()
+
+	var watcherErrors int
+
+	// Events are coalesced until no event is received during the debounce duration,
+	// and until the MinReloadInterval has elapsed since the last reload
+	var pendingEvent fsnotify.Event
+	var debounce *time.Timer
+	var debounceC <-chan time.Time
+	var lastReload time.Time
+
+	reload := func(evt fsnotify.Event) {
+		lastReload = time.Now()
+		callback(configurationChan, evt, stop)
 	}
 
-	sendConfigToChannel(configurationChan, configuration)
-}
+	schedule := func(evt fsnotify.Event) {
+		delay := time.Duration(p.DebounceDuration)
+		if wait := time.Duration(p.MinReloadInterval) - time.Since(lastReload); wait > delay {
+			delay = wait
+		}
+		if delay <= 0 && debounceC == nil {
+			reload(evt)
+			return
+		}
 
-func sendConfigToChannel(configurationChan chan<- types.ConfigMessage, configuration *types.Configuration) {
-	configurationChan <- types.ConfigMessage{
-		ProviderName:  "file",
-		Configuration: configuration,
+		if debounceC != nil {
+			evt = p.coalesceEvents(pendingEvent, evt)
+		}
+		pendingEvent = evt
+		if debounce != nil {
+			debounce.Stop()
+		}
+		debounce = time.NewTimer(delay)
+		debounceC = debounce.C
+	}
+
+	for {
+		select {
+		case <-stop:
+			if debounce != nil {
+				debounce.Stop()
+			}
+			return false
+		case evt, ok := <-watcher.Events:
+			if !ok {
+				log.Error("File watcher stopped unexpectedly")
+				return true
+			}
+			watcherErrors = 0
+			if evt.Op&(fsnotify.Remove|fsnotify.Rename) != 0 && containsDirectory(roots, evt.Name) {
+				log.Errorf("Watched directory %s removed", evt.Name)
+				return true
+			}
+			if p.watchesFileDirectly() {
+				p.updateDirectWatch(watcher, evt)
+			}
+			if !p.isWatchedEvent(evt) {
+				continue
+			}
+			if p.hasDirectories() && evt.Op&fsnotify.Create == fsnotify.Create {
+				p.watchNewDirectory(watcher, evt.Name)
+			}
+			schedule(evt)
+		case <-debounceC:
+			debounceC = nil
+			reload(pendingEvent)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				log.Error("File watcher stopped unexpectedly")
+				return true
+			}
+			watcherErrors++
+			if watcherErrors >= maxWatcherErrors {
+				log.Errorf("File watcher failed with %d errors in a row, the last one being: %s", watcherErrors, err)
+				return true
+			}
+			// Events may have been lost, such as when the event queue overflows
+			log.Warnf("Watcher event error: %s, reloading the whole configuration", err)
+			schedule(fsnotify.Event{})
+		}
 	}
 }
 
-func loadFileConfig(filename string) (*types.Configuration, error) {
-	configuration := &types.Configuration{
-		Frontends: make(map[string]*types.Frontend),
-		Backends:  make(map[string]*types.Backend),
+// coalesceEvents returns the event reloading the changes of both events: the later one if they change the same path,
+// an event on their common directory if only the files of this sub-directory have to be loaded again,
+// or else an event without name, which reloads the whole configuration.
+func (p *Provider) coalesceEvents(first, second fsnotify.Event) fsnotify.Event {
+	if first.Name == second.Name {
+		return second
 	}
-	if _, err := toml.DecodeFile(filename, configuration); err != nil {
-		return nil, fmt.Errorf("error reading configuration file: %s", err)
+
+	firstSubtree, ok := p.changedSubtree(first.Name)
+	if !ok {
+		return fsnotify.Event{}
 	}
-	return configuration, nil
+	secondSubtree, ok := p.changedSubtree(second.Name)
+	if !ok {
+		return fsnotify.Event{}
+	}
+
+	directory := firstSubtree
+	for !isInDirectory(secondSubtree, directory) {
+		directory = filepath.Dir(directory)
+	}
+	if p.rootDirectory(directory) == "" || p.isConfiguredDirectory(directory) {
+		return fsnotify.Event{}
+	}
+	return fsnotify.Event{Name: directory, Op: fsnotify.Create}
 }
 
-func loadFileConfigFromDirectory(directory string, configuration *types.Configuration) (*types.Configuration, error) {
-	fileList, err := ioutil.ReadDir(directory)
+// restartWatcher creates a new watcher on the watched directories, retrying with an exponential backoff,
+// and returns it along with its roots. It returns a nil watcher if the provider is stopped meanwhile.
+func (p *Provider) restartWatcher(stop chan bool) (*fsnotify.Watcher, []string) {
+	backOff := p.watcherBackOff()
 
-	if err != nil {
-		return configuration, fmt.Errorf("unable to read directory %s: %v", directory, err)
-	}
+	for {
+		delay := backOff.NextBackOff()
+		log.Warnf("Restarting the file watcher in %s", delay)
 
-	if configuration == nil {
-		configuration = &types.Configuration{
-			Frontends: make(map[string]*types.Frontend),
-			Backends:  make(map[string]*types.Backend),
+		timer := time.NewTimer(delay)
+		select {
+		case <-stop:
+			timer.Stop()
+			return nil, nil
+		case <-timer.C:
 		}
+
+		directories, err := p.watchedDirectories()
+		if err == nil {
+			var watcher *fsnotify.Watcher
+			watcher, err = newWatcher(directories)
+			if err == nil {
+				log.Info("File watcher restarted")
+				return watcher, watchedRoots(directories)
+			}
+		}
+		log.Errorf("Unable to restart the file watcher: %v", err)
+	}
+}
+
+// watcherBackOff returns the backoff between the attempts to restart the watcher.
+func (p *Provider) watcherBackOff() *backoff.ExponentialBackOff {
+	backOff := backoff.NewExponentialBackOff()
+	backOff.MaxElapsedTime = 0
+	if p.WatcherRestartMinDelay > 0 {
+		backOff.InitialInterval = time.Duration(p.WatcherRestartMinDelay)
 	}
+	if p.WatcherRestartMaxDelay > 0 {
+		backOff.MaxInterval = time.Duration(p.WatcherRestartMaxDelay)
+	}
+	backOff.Reset()
+	return backOff
+}
 
-	configTLSMaps := make(map[*tls.Configuration]struct{})
-	for _, item := range fileList {
+// watchedDirectories returns the directories to watch: the directories and their sub-directories,
+// or the directories of the files, so that the files can be replaced atomically.
+// With DirectWatch, the file itself is watched instead of its directory, as long as it exists.
+func (p *Provider) watchedDirectories() ([]string, error) {
+	if p.TriggerFile != "" {
+		// Only the changes of the trigger file reload the configuration
+		return []string{filepath.Dir(p.TriggerFile)}, nil
+	}
 
-		if item.IsDir() {
-			configuration, err = loadFileConfigFromDirectory(filepath.Join(directory, item.Name()), configuration)
+	filename := p.resolved().filename
+	directories := []string{filepath.Dir(filename)}
+	if p.watchesFileDirectly() {
+		if _, err := os.Stat(filename); err == nil {
+			directories = []string{filename}
+		}
+	}
+	if p.hasDirectories() {
+		directories = nil
+		visited := make(map[string]struct{})
+		for _, directory := range p.directories() {
+			subDirectories, err := p.getDirectoriesRecursively(directory, visited)
 			if err != nil {
-				return configuration, fmt.Errorf("unable to load content configuration from subdirectory %s: %v", item, err)
+				return nil, err
 			}
+			directories = append(directories, subDirectories...)
+		}
+		if p.mergesFilename() && !containsDirectory(directories, filepath.Dir(filename)) {
+			directories = append(directories, filepath.Dir(filename))
+		}
+	} else if p.hasFiles() {
+		directories = p.filesDirectories()
+	} else if p.readsArchive() {
+		directories = []string{filepath.Dir(p.Archive)}
+	}
+
+	// The values and defaults files are watched along with the configuration
+	for _, directory := range p.settingsDirectories() {
+		if !containsDirectory(directories, directory) {
+			directories = append(directories, directory)
+		}
+	}
+	return directories, nil
+}
+
+// watchedRoots returns the watched directories whose parent directory is not watched.
+// Unlike the sub-directories, added back to the watcher on the changes of their parent, they are not watched anymore once removed.
+func watchedRoots(directories []string) []string {
+	var roots []string
+	for _, directory := range directories {
+		if containsDirectory(directories, filepath.Dir(directory)) {
 			continue
-		} else if !strings.HasSuffix(item.Name(), ".toml") {
-			continue
 		}
+		if fileInfo, err := os.Stat(directory); err == nil && fileInfo.IsDir() {
+			roots = append(roots, directory)
+		}
+	}
+	return roots
+}
 
-		var c *types.Configuration
-		c, err = loadFileConfig(path.Join(directory, item.Name()))
+// settingsDirectories returns the directories of the TemplateValuesFile and of the DefaultsFile, if set.
+func (p *Provider) settingsDirectories() []string {
+	var directories []string
+	for _, file := range []string{p.TemplateValuesFile, p.DefaultsFile} {
+		if file != "" {
+			directories = append(directories, filepath.Dir(file))
+		}
+	}
+	return directories
+}
 
-		if err != nil {
-			return configuration, err
+// containsDirectory returns true if the directory is one of the directories.
+func containsDirectory(directories []string, directory string) bool {
+	for _, item := range directories {
+		if filepath.Clean(item) == filepath.Clean(directory) {
+			return true
 		}
+	}
+	return false
+}
 
-		for backendName, backend := range c.Backends {
-			if _, exists := configuration.Backends[backendName]; exists {
-				log.Warnf("Backend %s already configured, skipping", backendName)
-			} else {
-				configuration.Backends[backendName] = backend
-			}
+// watchesFileDirectly returns true if the configuration file itself is watched rather than its directory.
+func (p *Provider) watchesFileDirectly() bool {
+	return p.DirectWatch && p.readsSingleFile() && p.TriggerFile == ""
+}
+
+// updateDirectWatch keeps watching the configuration file when it is replaced, removed or renamed,
+// since the watch of a file follows its inode and not its path.
+// When the file does not exist anymore, its directory is watched until the file is created again.
+func (p *Provider) updateDirectWatch(watcher *fsnotify.Watcher, evt fsnotify.Event) {
+	filename := p.resolved().filename
+	if filepath.Clean(evt.Name) != filepath.Clean(filename) {
+		return
+	}
+
+	directory := filepath.Dir(filename)
+	switch {
+	case evt.Op&fsnotify.Create != 0:
+		// The file was created in the watched directory
+		if err := watcher.Add(filename); err != nil {
+			log.Errorf("Unable to watch file %s: %v", filename, err)
+			return
+		}
+		if !containsDirectory(p.settingsDirectories(), directory) {
+			removeWatch(watcher, directory)
+		}
+	case evt.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		// The watch of a renamed file follows it, its events only reload the configuration file again
+		if err := watcher.Add(filename); err == nil {
+			// The file was replaced
+			return
+		}
+		log.Debugf("File %s removed, watching its directory until it is created again", filename)
+		if err := watcher.Add(directory); err != nil {
+			log.Errorf("Unable to watch directory %s: %v", directory, err)
 		}
+	}
+}
 
-		for frontendName, frontend := range c.Frontends {
-			if _, exists := configuration.Frontends[frontendName]; exists {
-				log.Warnf("Frontend %s already configured, skipping", frontendName)
-			} else {
-				configuration.Frontends[frontendName] = frontend
-			}
+// removeWatch stops watching the path.
+// The watcher waits for its events to be consumed before removing a watch, so it can not be done while processing them.
+func removeWatch(watcher *fsnotify.Watcher, name string) {
+	safe.Go(func() {
+		watcher.Remove(name)
+	})
+}
+
+// watchNewDirectory adds the created path to the watcher if it is a directory, along with its sub-directories.
+func (p *Provider) watchNewDirectory(watcher *fsnotify.Watcher, name string) {
+	fileInfo, err := os.Stat(name)
+	if err != nil || !fileInfo.IsDir() {
+		return
+	}
+	if fileInfo, err = os.Lstat(name); err == nil && fileInfo.Mode()&os.ModeSymlink != 0 && !p.FollowSymlinks {
+		return
+	}
+
+	directories, err := p.getDirectoriesRecursively(name, nil)
+	if err != nil {
+		log.Errorf("Unable to watch directory %s: %v", name, err)
+		return
+	}
+	for _, directory := range directories {
+		if err := watcher.Add(directory); err != nil {
+			log.Errorf("Unable to watch directory %s: %v", directory, err)
+		}
+	}
+}
+
+// watcherCallback loads the configuration again after the event and sends it if it changed.
+// A configuration which fails to load is loaded again up to ReloadRetries times, as the change may have been notified
+// while a file was still being written, or reading it may fail transiently on network file systems.
+// The reload lock is released while waiting between the attempts, which stop when the provider is stopped.
+func (p *Provider) watcherCallback(configurationChan chan<- types.ConfigMessage, event fsnotify.Event, stop chan bool) {
+	delay := time.Duration(p.ReloadRetryDelay)
+	for retry := 1; ; retry++ {
+		err := p.reloadFilesLocked(configurationChan, event, triggerWatch)
+		if err == nil {
+			return
+		}
+		if retry > p.ReloadRetries {
+			log.Errorf("Error occurred during watcher callback: %s", err)
+			p.markStale()
+			return
 		}
 
-		for _, conf := range c.TLSConfiguration {
-			if _, exists := configTLSMaps[conf]; exists {
-				log.Warnf("TLS Configuration %v already configured, skipping", conf)
-			} else {
-				configTLSMaps[conf] = struct{}{}
+		log.Debugf("Unable to load the configuration after the change of %s, retrying in %s (%d/%d): %v", event.Name, delay, retry, p.ReloadRetries, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// reloadFilesLocked loads the configuration again after the event and sends it if it changed, holding the reload lock,
+// and returns the error loading it.
+func (p *Provider) reloadFilesLocked(configurationChan chan<- types.ConfigMessage, event fsnotify.Event, trigger string) error {
+	p.reloadLock.Lock()
+	defer p.reloadLock.Unlock()
+
+	if p.skipsReload() {
+		return nil
+	}
+	return p.loadFilesAndSend(configurationChan, event, trigger)
+}
+
+// Reload loads the whole configuration again and sends it if it changed, like a change of the watched files does.
+// It can be called concurrently with the reloads triggered by the watched files or by the polling of the RemoteURL.
+func (p *Provider) Reload(configurationChan chan<- types.ConfigMessage) {
+	if p.readsStdin() {
+		log.Warn("The configuration read from stdin can not be reloaded")
+		return
+	}
+
+	p.reloadLock.Lock()
+	defer p.reloadLock.Unlock()
+
+	if p.skipsReload() {
+		return
+	}
+	if p.readsRemote() {
+		p.reloadRemote(configurationChan, false, triggerReload)
+		return
+	}
+	p.reloadFiles(configurationChan, fsnotify.Event{}, triggerReload)
+}
+
+// ForceReload loads the whole configuration again, ignoring the cached files, and sends it even if it did not change,
+// on the channel given to Provide, for example when Træfik receives SIGHUP.
+// It is safe to call it several times, and concurrently with the reloads triggered by the watched files,
+// by the polling of the RemoteURL or by Reload: the reloads are serialized, and the last configuration sent is the latest one loaded.
+// It returns an error if the provider is not started, or if the configuration can not be loaded, the last configuration sent staying in use.
+// It does nothing while the first configuration waits for the StartupDelay, this configuration loading the files as they are then.
+func (p *Provider) ForceReload() error {
+	configurationChan, ok := p.configurationChan.Get().(chan<- types.ConfigMessage)
+	if !ok {
+		return errors.New("the file provider is not started")
+	}
+	if p.readsStdin() {
+		return errors.New("the configuration read from stdin can not be reloaded")
+	}
+
+	p.reloadLock.Lock()
+	defer p.reloadLock.Unlock()
+
+	if p.skipsReload() {
+		return nil
+	}
+	start := time.Now()
+	configuration, err := p.buildConfiguration()
+	if err != nil {
+		p.markStale()
+		return err
+	}
+	p.observeLoadDuration(configuration, triggerForce, time.Since(start))
+
+	p.sendConfigToChannel(configurationChan, configuration, triggerForce)
+	return nil
+}
+
+// reloadFiles loads the configuration again after the event, the whole configuration for an event without name,
+// and sends it if it changed.
+func (p *Provider) reloadFiles(configurationChan chan<- types.ConfigMessage, event fsnotify.Event, trigger string) {
+	if err := p.loadFilesAndSend(configurationChan, event, trigger); err != nil {
+		log.Errorf("Error occurred during watcher callback: %s", err)
+		p.markStale()
+	}
+}
+
+// loadFilesAndSend loads the configuration again after the event and sends it if it changed,
+// and returns the error loading it, after which the last configuration sent stays in use.
+func (p *Provider) loadFilesAndSend(configurationChan chan<- types.ConfigMessage, event fsnotify.Event, trigger string) error {
+	watchItems := []string{p.resolved().filename}
+	if p.hasDirectories() {
+		watchItems = p.directories()
+	} else if p.hasFiles() {
+		watchItems = p.resolved().files
+	} else if p.readsArchive() {
+		watchItems = []string{p.Archive}
+	} else if p.readsStaticOnly() {
+		watchItems = nil
+	}
+
+	for _, watchItem := range watchItems {
+		if _, err := os.Stat(watchItem); err != nil {
+			if p.hasDirectories() && os.IsNotExist(err) {
+				log.Warnf("Configured directory %s removed, keeping the previous configuration", watchItem)
+				return nil
 			}
+			log.Debugf("Unable to watch %s : %v", watchItem, err)
+			return nil
 		}
+	}
+
+	start := time.Now()
+	configuration, err := p.reloadConfiguration(event)
+	if err != nil {
+		return err
+	}
+	p.observeLoadDuration(configuration, trigger, time.Since(start))
 
+	if p.isLastConfiguration(configuration) {
+		changed := event.Name
+		if changed == "" {
+			changed = p.configurationSource()
+		}
+		log.Debugf("Configuration unchanged after the change of %s, skipping", changed)
+		p.resetStale()
+		return nil
 	}
-	for conf := range configTLSMaps {
-		configuration.TLSConfiguration = append(configuration.TLSConfiguration, conf)
+
+	p.sendConfigToChannel(configurationChan, configuration, trigger)
+	return nil
+}
+
+// isLastConfiguration returns true if the configuration is the same as the last one sent.
+func (p *Provider) isLastConfiguration(configuration *types.Configuration) bool {
+	lastHash, ok := p.lastConfigurationHash.Get().(string)
+	if !ok {
+		return false
 	}
-	return configuration, nil
+
+	hash, err := configurationHash(configuration)
+	return err == nil && hash == lastHash
+}
+
+// configurationHash returns the SHA-256 hash of the JSON encoding of the configuration, which sorts the map keys.
+func configurationHash(configuration *types.Configuration) (string, error) {
+	encoded, err := json.Marshal(configuration)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// markStale records a failed reload, after which the last configuration sent stays in use.
+func (p *Provider) markStale() {
+	staleReloads := atomic.AddInt32(&p.staleReloads, 1)
+	if p.MetricsRegistry != nil {
+		p.MetricsRegistry.ConfigStaleReloadsGauge().With("provider", p.providerName()).Set(float64(staleReloads))
+	}
+
+	if p.lastConfiguration.Get() != nil {
+		log.Warnf("Træfik is continuing with the previous file configuration, %d reloads failed since it was loaded", staleReloads)
+	}
+}
+
+// resetStale records that the configuration in use is up to date.
+func (p *Provider) resetStale() {
+	atomic.StoreInt32(&p.staleReloads, 0)
+	if p.MetricsRegistry != nil {
+		p.MetricsRegistry.ConfigStaleReloadsGauge().With("provider", p.providerName()).Set(0)
+	}
+}
+
+// observeLoadDuration logs how long loading the configuration took, along with the number of files and backends,
+// and records it in the metrics. The retries waiting for a file being written are not included.
+func (p *Provider) observeLoadDuration(configuration *types.Configuration, trigger string, duration time.Duration) {
+	var files int
+	if stats, ok := p.loadStats.Get().(*loadStats); ok {
+		files = len(stats.files)
+	}
+	log.Infof("Loaded the file configuration in %s (%s): %d files, %d backends", duration, trigger, files, len(configuration.Backends))
+
+	if p.MetricsRegistry != nil {
+		p.MetricsRegistry.ConfigReloadDurationHistogram().With("provider", p.providerName(), "trigger", trigger).Observe(duration.Seconds())
+	}
+}
+
+func (p *Provider) sendConfigToChannel(configurationChan chan<- types.ConfigMessage, configuration *types.Configuration, trigger string) {
+	// The loaded configuration is hashed, to be compared with the next loaded one
+	if hash, err := configurationHash(configuration); err == nil {
+		p.lastConfigurationHash.Set(hash)
+	} else {
+		log.Debugf("Unable to hash the configuration: %v", err)
+		p.lastConfigurationHash.Set(nil)
+	}
+	if p.OnConfiguration != nil {
+		p.OnConfiguration(configuration)
+	}
+
+	if previous, ok := p.lastConfiguration.Get().(*types.Configuration); ok && p.LogConfigDiff {
+		logConfigurationDiff(previous, configuration)
+	}
+	if stats, ok := p.loadStats.Get().(*loadStats); ok {
+		log.Info(loadSummary(configuration, p.configurationSource(), stats))
+	}
+
+	p.lastConfiguration.Set(configuration)
+	p.resetStale()
+
+	if p.MetricsRegistry != nil {
+		p.MetricsRegistry.ConfigReloadsCounter().With("provider", p.providerName(), "trigger", trigger).Add(1)
+	}
+
+	configurationChan <- types.ConfigMessage{
+		ProviderName:  p.providerName(),
+		Configuration: configuration,
+	}
+}
+
+// loadFileConfig loads the configuration file and the files it includes.
+func (p *Provider) loadFileConfig(filename string) (*types.Configuration, error) {
+	warns := p.newWarnings()
+	configuration, _, err := p.loadFileConfigWithIncludes(filename, nil, nil, warns)
+	if err != nil {
+		return nil, err
+	}
+	return configuration, warns.err()
+}
+
+// loadCachedFile loads the configuration file along with the list of the files it was loaded from.
+func (p *Provider) loadCachedFile(filename string) (*cachedFile, error) {
+	dependencies := make(map[string]struct{})
+	warns := p.newWarnings()
+	configuration, origins, err := p.loadFileConfigWithIncludes(filename, nil, dependencies, warns)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedFile{
+		configuration: configuration,
+		dependencies:  dependencies,
+		modTime:       newestModTime(dependencies),
+		origins:       origins,
+		warnings:      warns,
+	}, nil
+}
+
+// loadFileConfigWithIncludes loads the configuration file, then merges the files it includes,
+// and returns the file defining each of its frontends and backends.
+// The includeStack holds the files including this one, to detect include cycles.
+// If not nil, the files read are added to dependencies. The warnings raised are added to warns.
+func (p *Provider) loadFileConfigWithIncludes(filename string, includeStack []string, dependencies map[string]struct{}, warns *warnings) (*types.Configuration, *configurationOrigins, error) {
+	for _, including := range includeStack {
+		if filepath.Clean(including) == filepath.Clean(filename) {
+			return nil, nil, fmt.Errorf("include cycle detected: %s -> %s", strings.Join(includeStack, " -> "), filename)
+		}
+	}
+
+	if dependencies != nil {
+		dependencies[filepath.Clean(filename)] = struct{}{}
+	}
+
+	fc, err := p.readFileContent(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dependencies != nil {
+		for _, templateFile := range fc.templateFiles {
+			dependencies[templateFile] = struct{}{}
+		}
+	}
+	if fc.Disabled {
+		log.Infof("Skipping disabled configuration file %s", filename)
+		configuration := disabledConfiguration()
+		return configuration, newConfigurationOrigins(filename, configuration), nil
+	}
+	warnDuplicateServers(filename, &fc.Configuration, warns)
+	resolveCertificatePaths(filename, &fc.Configuration)
+	if err := p.checkKeyPairs(filename, &fc.Configuration); err != nil {
+		return nil, nil, err
+	}
+
+	configuration := &fc.Configuration
+	origins := newConfigurationOrigins(filename, configuration)
+	if len(fc.Include.Files) == 0 {
+		return configuration, origins, nil
+	}
+
+	strategy, err := p.mergeStrategy()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsSources := make(map[string]string)
+	configuration.TLSConfiguration = mergeTLSConfigurations(nil, tlsSources, filename, configuration.TLSConfiguration, warns)
+
+	includeStack = append(includeStack, filename)
+	for _, includedFile := range fc.Include.Files {
+		includedFile = resolvePath(filename, includedFile)
+		c, includedOrigins, err := p.loadFileConfigWithIncludes(includedFile, includeStack, dependencies, warns)
+		if _, ok := err.(*fileTooLargeError); ok {
+			// Unlike the files of a directory, an included file larger than the MaxFileSize is not skipped
+			return nil, nil, fmt.Errorf("%v, included by %s", err, filename)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for backendName, backend := range c.Backends {
+			if err := mergeBackend(strategy, configuration.Backends, origins.backends, backendName, includedOrigins.backends[backendName], backend, warns); err != nil {
+				return nil, nil, err
+			}
+		}
+		for frontendName, frontend := range c.Frontends {
+			mergeFrontend(strategy, configuration.Frontends, origins.frontends, frontendName, includedOrigins.frontends[frontendName], frontend, warns)
+		}
+		configuration.TLSConfiguration = mergeTLSConfigurations(configuration.TLSConfiguration, tlsSources, includedFile, c.TLSConfiguration, warns)
+	}
+	return configuration, origins, nil
+}
+
+// readFileContent reads and decodes the configuration file, rendering it first if it is a template.
+func (p *Provider) readFileContent(filename string) (*fileContent, error) {
+	content, err := p.readConfigFile(filename)
+	if _, ok := err.(*fileTooLargeError); ok {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading configuration file %s: %v", filename, err)
+	}
+
+	content, err = p.decrypt(filename, content)
+	if err != nil {
+		return nil, fmt.Errorf("error reading configuration file %s: %v", filename, err)
+	}
+	if err := checkTextContent(filename, content); err != nil {
+		return nil, err
+	}
+
+	fc, err := p.decodeContent(filename, content)
+	if err != nil {
+		return nil, fmt.Errorf("error reading configuration file %s: %v", filename, err)
+	}
+	return fc, nil
+}
+
+// decodeContent decodes the content in the format of the filename extension, rendering it first if it is a template.
+func (p *Provider) decodeContent(filename string, content []byte) (*fileContent, error) {
+	// Files without a known extension, such as the global configuration file, are read as TOML
+	f, ok := formatFromFilename(filename)
+	if !ok {
+		f = formatTOML
+	}
+
+	if !isTemplateFile(filename) {
+		return p.decodeRenderedContent(content, f)
+	}
+
+	rendered, templateFiles, err := renderTemplate(filename, string(content), p.templateValues.Get())
+	if err != nil {
+		return nil, err
+	}
+
+	fc, err := p.decodeRenderedContent([]byte(rendered), f)
+	if err != nil {
+		err = renderedLineHint(rendered, f, err)
+		if p.DumpRenderedTemplates {
+			return nil, dumpRenderedTemplate(filename, rendered, err)
+		}
+		return nil, err
+	}
+	fc.templateFiles = templateFiles
+	return fc, nil
+}
+
+// decodeRenderedContent decodes the content, rendered if it is a template, in the given format.
+func (p *Provider) decodeRenderedContent(content []byte, f format) (*fileContent, error) {
+	if !p.LenientDecode {
+		keys, err := unknownKeys(content, f)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) > 0 {
+			return nil, fmt.Errorf("unknown top-level keys: %s", strings.Join(keys, ", "))
+		}
+	}
+
+	if err := p.validateSchema(content, f); err != nil {
+		return nil, err
+	}
+
+	fc, err := decodeFileContent(content, f)
+	if err != nil {
+		return nil, err
+	}
+	p.applyProfile(fc)
+	return fc, nil
+}
+
+// readFile returns the content of the file, decompressed if it is a gzip-compressed one.
+// The stdinFilename reads the standard input.
+func readFile(filename string) ([]byte, error) {
+	if filename == stdinFilename {
+		return ioutil.ReadAll(stdin)
+	}
+	if !isGzipFile(filename) {
+		return ioutil.ReadFile(filename)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+// resolvePath returns the path of name, relative to the directory of the file referencing it if it is not absolute.
+func resolvePath(referencingFile, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(filepath.Dir(referencingFile), name)
+}
+
+// isConfigFile returns true if the file extension is one of the supported ones.
+func isConfigFile(filename string) bool {
+	_, ok := formatFromFilename(filename)
+	return ok || isTemplateFile(filename)
+}
+
+// isHiddenFile returns true for the dotfiles, and the backup files of editors ending with a tilde.
+func isHiddenFile(filename string) bool {
+	name := filepath.Base(filename)
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~")
+}
+
+// isSelectedFile returns true if the file has to be loaded in directory mode.
+func (p *Provider) isSelectedFile(filename string) bool {
+	if !p.hasSelectedExtension(filename) || !p.IncludeHiddenFiles && isHiddenFile(filename) || isPartialTemplate(filename) || p.isIgnored(filename) {
+		return false
+	}
+	if p.FilePattern == "" {
+		return true
+	}
+	matched, err := filepath.Match(p.FilePattern, filepath.Base(filename))
+	return err == nil && matched
+}
+
+// isWatchedEvent returns true if the event may affect the configuration.
+// In single file mode, the directory of the file is watched so that the file can be replaced atomically:
+// renaming a temporary file to the configuration file produces a Create event for the configuration file.
+func (p *Provider) isWatchedEvent(evt fsnotify.Event) bool {
+	if p.TriggerFile != "" {
+		// Removing the trigger file does not signal a new configuration
+		return filepath.Clean(evt.Name) == filepath.Clean(p.TriggerFile) && evt.Op&(fsnotify.Write|fsnotify.Create) != 0
+	}
+	if p.isTemplateValuesFile(evt.Name) || p.isDefaultsFile(evt.Name) {
+		return true
+	}
+	if p.hasDirectories() {
+		if p.mergesFilename() && filepath.Clean(evt.Name) == filepath.Clean(p.resolved().filename) {
+			return true
+		}
+		return p.isWatchedPath(evt.Name) || p.isCachedDependency(evt.Name)
+	}
+	if p.hasFiles() {
+		return p.isListedFile(evt.Name)
+	}
+	if p.readsArchive() {
+		return p.isArchive(evt.Name)
+	}
+
+	_, evtFileName := filepath.Split(evt.Name)
+	_, confFileName := filepath.Split(p.resolved().filename)
+	return evtFileName == confFileName
+}
+
+// isWatchedPath returns true if a change of the path may affect the configuration in directory mode:
+// the path is either a selected file, the disabled marker of one, a partial template which may be included, or a directory.
+// Removed paths without extension are considered as directories since they can not be checked anymore.
+func (p *Provider) isWatchedPath(name string) bool {
+	if p.isIgnoreFile(name) || p.isSelectedFile(name) || isDisabledMarker(name) || isPartialTemplate(name) {
+		return true
+	}
+	if p.isIgnored(name) {
+		return false
+	}
+	fileInfo, err := os.Stat(name)
+	if err != nil {
+		return filepath.Ext(name) == ""
+	}
+	return fileInfo.IsDir()
+}
+
+// loadFileConfigFromDirectory loads and merges the configuration files of the directory and its sub-directories.
+func (p *Provider) loadFileConfigFromDirectory(directory string) (*types.Configuration, error) {
+	cache, err := p.loadDirectory(directory)
+	if err != nil {
+		return nil, err
+	}
+	return p.mergeFileCache(cache)
+}
+
+// loadDirectory loads the configuration files of the directory and its sub-directories into a new cache.
+func (p *Provider) loadDirectory(directory string) (*fileCache, error) {
+	return p.loadDirectories([]string{directory})
+}
+
+// loadDirectories loads the configuration files of the directories and their sub-directories into a new cache,
+// one directory after the other, so that the later directories are merged over the former ones.
+func (p *Provider) loadDirectories(directories []string) (*fileCache, error) {
+	return p.loadDirectoriesReusing(directories, nil, "")
+}
+
+// loadDirectoriesReusing loads the configuration files of the directories like loadDirectories,
+// reusing the files of the previous cache which are neither in the changed subtree nor include a file of it.
+// The files are listed again, so that the load order is unchanged, but only the files of the subtree are parsed again.
+func (p *Provider) loadDirectoriesReusing(directories []string, previous *fileCache, subtree string) (*fileCache, error) {
+	if _, err := p.mergeStrategy(); err != nil {
+		return nil, err
+	}
+
+	patterns := make(map[string]ignorePatterns, len(directories))
+	for _, directory := range directories {
+		if fileInfo, err := os.Stat(directory); err == nil && !fileInfo.IsDir() {
+			return nil, fmt.Errorf("directory %q is not a directory, use filename to load a single file", directory)
+		}
+
+		directoryPatterns, err := readIgnoreFile(directory)
+		if err != nil {
+			return nil, err
+		}
+		patterns[filepath.Clean(directory)] = directoryPatterns
+	}
+	p.ignorePatterns.Set(patterns)
+
+	state := &loadState{visited: make(map[string]struct{})}
+	for _, directory := range directories {
+		listed := len(state.files)
+		if err := p.listDirectoryFiles(directory, state); err != nil {
+			return nil, err
+		}
+		if p.RequireAtLeastOneFile && len(state.files) == listed {
+			return nil, p.noConfigurationFileError(directory)
+		}
+	}
+
+	// Files are parsed concurrently, but added in load order,
+	// so that the first error and the merge of the configurations do not depend on the parsing order
+	cache := newFileCache()
+	entries, errs := p.loadChangedCachedFiles(state.files, previous, subtree)
+	for i, file := range state.files {
+		if skipTooLargeFile(errs[i]) {
+			continue
+		}
+		if errs[i] != nil {
+			if !p.SkipInvalidFiles {
+				return nil, errs[i]
+			}
+			logSkippedFile(errs[i])
+			state.invalidFiles = append(state.invalidFiles, file)
+			continue
+		}
+		cache.add(file, entries[i])
+	}
+	cache.directories = len(state.visited)
+
+	if len(state.invalidFiles) > 0 {
+		log.Errorf("Skipped %d invalid configuration files: %s", len(state.invalidFiles), strings.Join(state.invalidFiles, ", "))
+	}
+	return cache, nil
+}
+
+// noConfigurationFileError returns the error of a directory without any configuration file to load,
+// which usually means a wrong path or wrong extensions.
+func (p *Provider) noConfigurationFileError(directory string) error {
+	extensions := strings.Join(p.extensions(), ", ")
+	if p.FilePattern != "" {
+		return fmt.Errorf("no configuration file matching the pattern %q found in directory %s, searched extensions: %s", p.FilePattern, directory, extensions)
+	}
+	return fmt.Errorf("no configuration file found in directory %s, searched extensions: %s", directory, extensions)
+}
+
+// listDirectoryFiles lists the configuration files of the directory, then the ones of its sub-directories.
+// Files and sub-directories are both listed in lexical order,
+// so that the resolution of frontends and backends defined several times is predictable.
+func (p *Provider) listDirectoryFiles(directory string, state *loadState) error {
+	if p.exceedsMaxDepth(directory) {
+		return nil
+	}
+	if p.isIgnored(directory) {
+		log.Debugf("Skipping ignored directory %s", directory)
+		return nil
+	}
+	if !markVisited(state.visited, directory) {
+		log.Debugf("Directory %s already loaded, skipping", directory)
+		return nil
+	}
+
+	files, subDirectories, err := p.readDirectory(directory)
+	if err == errUnreadableDirectory {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if !p.isSelectedFile(file) {
+			log.Debugf("Skipping file %s, hidden, ignored, a partial template or not matching the extensions or the file pattern", file)
+			continue
+		}
+		if hasDisabledMarker(file) {
+			log.Infof("Skipping configuration file %s disabled by %s", file, file+disabledMarkerExtension)
+			continue
+		}
+
+		state.files = append(state.files, file)
+	}
+
+	for _, subDirectory := range subDirectories {
+		if p.isOverrideDirectory(subDirectory) {
+			log.Debugf("Skipping override directory %s, loaded last", subDirectory)
+			continue
+		}
+		// Errors already name the offending file or directory
+		if err := p.listDirectoryFiles(subDirectory, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadChangedCachedFiles loads the configuration files like loadCachedFiles, except the files reused from the previous cache.
+func (p *Provider) loadChangedCachedFiles(files []string, previous *fileCache, subtree string) ([]*cachedFile, []error) {
+	if previous == nil {
+		return p.loadCachedFiles(files)
+	}
+
+	entries := make([]*cachedFile, len(files))
+	errs := make([]error, len(files))
+
+	var changedFiles []string
+	var changedIndexes []int
+	for i, file := range files {
+		if entry := previous.reusableEntry(file, subtree); entry != nil {
+			entries[i] = entry
+			continue
+		}
+		changedFiles = append(changedFiles, file)
+		changedIndexes = append(changedIndexes, i)
+	}
+
+	changedEntries, changedErrs := p.loadCachedFiles(changedFiles)
+	for i, index := range changedIndexes {
+		entries[index], errs[index] = changedEntries[i], changedErrs[i]
+	}
+	return entries, errs
+}
+
+// loadCachedFiles loads the configuration files with at most LoadConcurrency files parsed at once,
+// and returns the loaded files and the errors in the order of the files.
+func (p *Provider) loadCachedFiles(files []string) ([]*cachedFile, []error) {
+	entries := make([]*cachedFile, len(files))
+	errs := make([]error, len(files))
+
+	workers := p.LoadConcurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				entries[index], errs[index] = p.loadCachedFile(files[index])
+			}
+		}()
+	}
+
+	for index := range files {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	return entries, errs
+}
+
+// mergeFileCache merges the configurations of the cached files, in load order.
+func (p *Provider) mergeFileCache(cache *fileCache) (*types.Configuration, error) {
+	strategy, err := p.mergeStrategy()
+	if err != nil {
+		return nil, err
+	}
+
+	configuration := &types.Configuration{
+		Frontends: make(map[string]*types.Frontend),
+		Backends:  make(map[string]*types.Backend),
+	}
+
+	origins := &configurationOrigins{
+		frontends: make(map[string]string),
+		backends:  make(map[string]string),
+	}
+	tlsSources := make(map[string]string)
+	warns := p.newWarnings()
+	for _, file := range cache.files {
+		entry := cache.entries[file]
+		c := entry.configuration
+		warns.merge(entry.warnings)
+
+		fileStrategy := strategy
+		if p.isOverrideFile(file) {
+			fileStrategy = mergeStrategyOverride
+		}
+
+		var namespace string
+		if p.NamespaceByFile {
+			namespace = p.fileNamespace(file)
+		}
+
+		for backendName, backend := range c.Backends {
+			if err := mergeBackend(fileStrategy, configuration.Backends, origins.backends, namespacedName(namespace, backendName), entry.origins.backends[backendName], backend, warns); err != nil {
+				return nil, err
+			}
+		}
+
+		for frontendName, frontend := range c.Frontends {
+			if namespace != "" {
+				frontend = namespaceFrontend(namespace, frontend, c.Backends)
+			}
+			mergeFrontend(fileStrategy, configuration.Frontends, origins.frontends, namespacedName(namespace, frontendName), entry.origins.frontends[frontendName], frontend, warns)
+		}
+
+		configuration.TLSConfiguration = mergeTLSConfigurations(configuration.TLSConfiguration, tlsSources, file, c.TLSConfiguration, warns)
+	}
+	if err := warns.err(); err != nil {
+		return nil, err
+	}
+
+	p.setOrigins(origins)
+	return configuration, nil
+}
+
+// getDirectoriesRecursively returns the directory and all its sub-directories.
+// The visited directories are tracked to prevent loops through symbolic links.
+func (p *Provider) getDirectoriesRecursively(directory string, visited map[string]struct{}) ([]string, error) {
+	if visited == nil {
+		visited = make(map[string]struct{})
+	}
+	if p.exceedsMaxDepth(directory) || p.isIgnored(directory) || !markVisited(visited, directory) {
+		return nil, nil
+	}
+
+	_, subDirectories, err := p.readDirectory(directory)
+	if err == errUnreadableDirectory {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	directories := []string{directory}
+	for _, subDirectory := range subDirectories {
+		subDirectories, err := p.getDirectoriesRecursively(subDirectory, visited)
+		if err != nil {
+			return nil, err
+		}
+		directories = append(directories, subDirectories...)
+	}
+	return directories, nil
+}
+
+// exceedsMaxDepth returns true if the directory is deeper than MaxDepth in its configured directory.
+func (p *Provider) exceedsMaxDepth(directory string) bool {
+	if p.MaxDepth <= 0 {
+		return false
+	}
+
+	root := p.rootDirectory(directory)
+	if root == "" {
+		return false
+	}
+
+	relativePath, err := filepath.Rel(root, directory)
+	if err != nil {
+		return false
+	}
+
+	depth := 1
+	if relativePath != "." {
+		depth += len(strings.Split(relativePath, string(filepath.Separator)))
+	}
+	if depth <= p.MaxDepth {
+		return false
+	}
+
+	log.Debugf("Directory %s is deeper than the maximum depth %d, skipping", directory, p.MaxDepth)
+	return true
+}
+
+// errUnreadableDirectory is returned when reading a sub-directory is not permitted, which is skipped.
+var errUnreadableDirectory = errors.New("unreadable directory")
+
+// listDirectory returns the content of the directory, listed by readDir if set.
+func (p *Provider) listDirectory(directory string) ([]os.FileInfo, error) {
+	if p.readDir != nil {
+		return p.readDir(directory)
+	}
+	return ioutil.ReadDir(directory)
+}
+
+// readDirectory returns the paths of the files and of the sub-directories of the directory, in lexical order.
+// Symbolic links to directories are considered as sub-directories only if FollowSymlinks is enabled.
+func (p *Provider) readDirectory(directory string) ([]string, []string, error) {
+	fileList, err := p.listDirectory(directory)
+	if os.IsPermission(err) && !p.isConfiguredDirectory(directory) {
+		// A single unreadable sub-directory must not prevent loading and watching the others
+		log.Warnf("Skipping unreadable directory %s: %v", directory, err)
+		return nil, nil, errUnreadableDirectory
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read directory %s: %v", directory, err)
+	}
+
+	var files, subDirectories []string
+	for _, item := range fileList {
+		itemPath := filepath.Join(directory, item.Name())
+
+		isDir := item.IsDir()
+		if item.Mode()&os.ModeSymlink != 0 {
+			fileInfo, err := os.Stat(itemPath)
+			if err != nil {
+				log.Warnf("Unable to resolve symbolic link %s: %v", itemPath, err)
+				continue
+			}
+			if fileInfo.IsDir() && !p.FollowSymlinks {
+				log.Debugf("Skipping symbolic link to directory %s", itemPath)
+				continue
+			}
+			isDir = fileInfo.IsDir()
+		}
+
+		if isDir {
+			subDirectories = append(subDirectories, itemPath)
+		} else {
+			files = append(files, itemPath)
+		}
+	}
+	return files, subDirectories, nil
+}
+
+// markVisited records the directory, once symbolic links are resolved,
+// and returns false if it has already been visited.
+func markVisited(visited map[string]struct{}, directory string) bool {
+	resolved, err := filepath.EvalSymlinks(directory)
+	if err != nil {
+		resolved = directory
+	}
+	if absolute, err := filepath.Abs(resolved); err == nil {
+		resolved = absolute
+	}
+
+	if _, exists := visited[resolved]; exists {
+		return false
+	}
+	visited[resolved] = struct{}{}
+	return true
 }
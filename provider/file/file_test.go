@@ -1,17 +1,30 @@
 package file
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/containous/flaeg"
+	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/safe"
 	"github.com/containous/traefik/types"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/fsnotify.v1"
 )
 
 func TestProvideSingleFileAndWatch(t *testing.T) {
@@ -30,7 +43,8 @@ func TestProvideSingleFileAndWatch(t *testing.T) {
 
 	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
 
-	provide(configurationChan, watch, withFile(tempFile))
+	stop := provide(configurationChan, watch, withFile(tempFile))
+	defer stop()
 
 	// Wait for initial message to be tested
 	err := waitForSignal(signal, 2*time.Second, "initial config")
@@ -51,6 +65,274 @@ func TestProvideSingleFileAndWatch(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestProvideSingleFileAndWatchAtomicRename(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	tempFile := createFile(t,
+		tempDir, "config.toml",
+		createFrontendConfiguration(expectedNumFrontends),
+		createBackendConfiguration(expectedNumBackends))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, withFile(tempFile))
+	defer stop()
+
+	// Wait for initial message to be tested
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	assert.NoError(t, err)
+
+	// Now replace the file with a temporary one
+	expectedNumFrontends = 1
+	expectedNumBackends = 1
+
+	tempFile2 := createFile(t,
+		tempDir, "config.toml.tmp",
+		createFrontendConfiguration(expectedNumFrontends),
+		createBackendConfiguration(expectedNumBackends))
+
+	err = os.Rename(tempFile2.Name(), tempFile.Name())
+	require.NoError(t, err)
+
+	err = waitForSignal(signal, 2*time.Second, "file renamed into place")
+	assert.NoError(t, err)
+}
+
+func TestProvideConfigReloadsMetric(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 1
+	expectedNumBackends := 1
+	expectedNumTLSConf := 0
+
+	tempFile := createFile(t,
+		tempDir, "simple.toml",
+		createFrontendConfiguration(expectedNumFrontends),
+		createBackendConfiguration(expectedNumBackends))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	registry := &collectingRegistry{Registry: metrics.NewVoidRegistry(), configReloadsCounter: &collectingCounter{}}
+	stop := provide(configurationChan, watch, withFile(tempFile), func(pvd *Provider) {
+		pvd.MetricsRegistry = registry
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"provider", "file", "trigger", triggerInitial}, registry.configReloadsCounter.labelValues())
+
+	// The file is replaced atomically, so that the watcher never reloads it partially written
+	expectedNumFrontends = 2
+	expectedNumBackends = 2
+	replaceFile(t,
+		tempDir, "simple.toml",
+		createFrontendConfiguration(expectedNumFrontends),
+		createBackendConfiguration(expectedNumBackends))
+
+	err = waitForSignal(signal, 2*time.Second, "watched config")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"provider", "file", "trigger", triggerWatch}, registry.configReloadsCounter.labelValues())
+	assert.Equal(t, float64(2), registry.configReloadsCounter.value())
+}
+
+func TestProvideConfigStaleReloadsMetric(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 1
+	expectedNumBackends := 1
+	expectedNumTLSConf := 0
+
+	tempFile := createFile(t,
+		tempDir, "simple.toml",
+		createFrontendConfiguration(expectedNumFrontends),
+		createBackendConfiguration(expectedNumBackends))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	registry := &collectingRegistry{
+		Registry:                metrics.NewVoidRegistry(),
+		configReloadsCounter:    &collectingCounter{},
+		configStaleReloadsGauge: &collectingGauge{values: make(chan float64, 10)},
+	}
+	stop := provide(configurationChan, watch, withFile(tempFile), withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.MetricsRegistry = registry
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), registry.configStaleReloadsGauge.waitForValue(t))
+
+	createFile(t, tempDir, "simple.toml", "[frontends")
+	assert.Equal(t, float64(1), registry.configStaleReloadsGauge.waitForValue(t))
+
+	expectedNumFrontends = 2
+	expectedNumBackends = 2
+	createFile(t,
+		tempDir, "simple.toml",
+		createFrontendConfiguration(expectedNumFrontends),
+		createBackendConfiguration(expectedNumBackends))
+
+	err = waitForSignal(signal, 2*time.Second, "watched config")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), registry.configStaleReloadsGauge.waitForValue(t))
+}
+
+func TestProvideConfigReloadDurationMetric(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 1
+	expectedNumBackends := 1
+	expectedNumTLSConf := 0
+
+	tempFile := createFile(t,
+		tempDir, "simple.toml",
+		createFrontendConfiguration(expectedNumFrontends),
+		createBackendConfiguration(expectedNumBackends))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	registry := &collectingRegistry{
+		Registry:                      metrics.NewVoidRegistry(),
+		configReloadsCounter:          &collectingCounter{},
+		configReloadDurationHistogram: &collectingHistogram{values: make(chan []string, 10)},
+	}
+	stop := provide(configurationChan, watch, withFile(tempFile), withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.MetricsRegistry = registry
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"provider", "file", "trigger", triggerInitial}, registry.configReloadDurationHistogram.waitForLabelValues(t))
+
+	// A failed reload is not timed
+	createFile(t, tempDir, "simple.toml", "[frontends")
+
+	expectedNumFrontends = 2
+	expectedNumBackends = 2
+	createFile(t,
+		tempDir, "simple.toml",
+		createFrontendConfiguration(expectedNumFrontends),
+		createBackendConfiguration(expectedNumBackends))
+
+	err = waitForSignal(signal, 2*time.Second, "watched config")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"provider", "file", "trigger", triggerWatch}, registry.configReloadDurationHistogram.waitForLabelValues(t))
+}
+
+func TestProvideSingleFileCreatedAfterStart(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 0
+	expectedNumBackends := 0
+	expectedNumTLSConf := 0
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, allowEmptyConfiguration, withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.Filename = filepath.Join(tempDir, "simple.toml")
+	})
+	defer stop()
+
+	// Wait for the initial empty configuration, sent once the directory is watched
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	assert.NoError(t, err)
+
+	expectedNumFrontends = 2
+	expectedNumBackends = 2
+	createFile(t,
+		tempDir, "simple.toml",
+		createFrontendConfiguration(expectedNumFrontends),
+		createBackendConfiguration(expectedNumBackends))
+
+	err = waitForSignal(signal, 2*time.Second, "created config")
+	assert.NoError(t, err)
+}
+
+func TestProvideProviderName(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	tempFile := createFile(t, tempDir, "simple.toml", createFrontendConfiguration(1), createBackendConfiguration(1))
+
+	configurationChan := make(chan types.ConfigMessage, 1)
+
+	pvd := &Provider{Filename: tempFile.Name()}
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	err := pvd.Provide(configurationChan, pool, nil)
+	require.NoError(t, err)
+
+	data := <-configurationChan
+	assert.Equal(t, "file", data.ProviderName)
+
+	pvd.ProviderName = "overlay"
+	err = pvd.Provide(configurationChan, pool, nil)
+	require.NoError(t, err)
+
+	data = <-configurationChan
+	assert.Equal(t, "overlay", data.ProviderName)
+}
+
+func TestProvideStdin(t *testing.T) {
+	defer func(previous io.Reader) { stdin = previous }(stdin)
+	stdin = strings.NewReader(createFrontendConfiguration(2) + createBackendConfiguration(2))
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, func(pvd *Provider) {
+		pvd.Filename = stdinFilename
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "stdin config")
+	assert.NoError(t, err)
+}
+
+func TestProvideDirectoryRemoved(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	createFile(t, tempDir, "frontend.toml", createFrontendConfiguration(expectedNumFrontends))
+	createFile(t, tempDir, "backend.toml", createBackendConfiguration(expectedNumBackends))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, withDirectory(tempDir), withDebounce(100*time.Millisecond))
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	assert.NoError(t, err)
+
+	err = os.RemoveAll(tempDir)
+	require.NoError(t, err)
+
+	// Must fail because the previous configuration is kept
+	err = waitForSignal(signal, 200*time.Millisecond, "removed directory")
+	assert.Error(t, err)
+}
+
 func TestProvideSingleFileAndNotWatch(t *testing.T) {
 	tempDir := createTempDir(t, "testfile")
 	defer os.RemoveAll(tempDir)
@@ -67,7 +349,8 @@ func TestProvideSingleFileAndNotWatch(t *testing.T) {
 
 	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
 
-	provide(configurationChan, withFile(tempFile))
+	stop := provide(configurationChan, withFile(tempFile))
+	defer stop()
 
 	// Wait for initial message to be tested
 	err := waitForSignal(signal, 2*time.Second, "initial config")
@@ -89,82 +372,1148 @@ func TestProvideSingleFileAndNotWatch(t *testing.T) {
 	assert.Error(t, err)
 }
 
-func TestProvideDirectoryAndWatch(t *testing.T) {
+func TestProvideDirectoryAndWatch(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 2
+
+	tempFile1 := createRandomFile(t, tempDir, createFrontendConfiguration(expectedNumFrontends))
+	tempFile2 := createRandomFile(t, tempDir, createBackendConfiguration(expectedNumBackends))
+	tempFile3 := createRandomFile(t, tempDir, createTLSConfiguration(expectedNumTLSConf))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, allowEmptyConfiguration, withDirectory(tempDir))
+	defer stop()
+
+	// Wait for initial config message to be tested
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	assert.NoError(t, err)
+
+	// Now remove the backends file
+	expectedNumFrontends = 2
+	expectedNumBackends = 0
+	expectedNumTLSConf = 2
+	os.Remove(tempFile2.Name())
+	err = waitForSignal(signal, 2*time.Second, "remove the backends file")
+	assert.NoError(t, err)
+
+	// Now remove the frontends file
+	expectedNumFrontends = 0
+	expectedNumBackends = 0
+	expectedNumTLSConf = 2
+	os.Remove(tempFile1.Name())
+	err = waitForSignal(signal, 2*time.Second, "remove the frontends file")
+	assert.NoError(t, err)
+
+	// Now remove the TLS configuration file
+	expectedNumFrontends = 0
+	expectedNumBackends = 0
+	expectedNumTLSConf = 0
+	os.Remove(tempFile3.Name())
+	err = waitForSignal(signal, 2*time.Second, "remove the TLS configuration file")
+	assert.NoError(t, err)
+}
+
+func TestProvideDirectoryAndWatchDebounce(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 0
+	expectedNumBackends := 0
+	expectedNumTLSConf := 0
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, allowEmptyConfiguration, withDirectory(tempDir), withDebounce(100*time.Millisecond))
+	defer stop()
+
+	// Wait for initial config message to be tested
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	assert.NoError(t, err)
+
+	// Now write several backends files in a row
+	expectedNumBackends = 5
+	for i := 1; i <= expectedNumBackends; i++ {
+		createFile(t, tempDir, fmt.Sprintf("backend%d.toml", i), fmt.Sprintf(`
+[backends.backend%[1]d.servers.server1]
+url = "http://172.17.0.%[1]d:80"
+`, i))
+	}
+
+	err = waitForSignal(signal, 2*time.Second, "write several backends files")
+	assert.NoError(t, err)
+
+	// Must fail because the events have been coalesced into a single reload
+	err = waitForSignal(signal, 200*time.Millisecond, "no more reload")
+	assert.Error(t, err)
+}
+
+func TestProvideDirectoryAndTemplateValuesWatchDebounce(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	rulesDir := createSubDir(t, tempDir, "rules")
+	valuesFile := createFile(t, tempDir, "values.yml", "url: http://172.17.0.1:80\n")
+	createFile(t, rulesDir, "backend1.toml.tmpl", `
+[backends.backend1.servers.server1]
+url = "{{ .url }}"
+`)
+
+	// Each load of the configuration goes through the Transform hook
+	var loads int32
+	configurationChan := make(chan types.ConfigMessage, 10)
+	stop := provide(configurationChan, watch, withDirectory(rulesDir), withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.TemplateValuesFile = valuesFile.Name()
+		pvd.Transform = func(configuration *types.Configuration) (*types.Configuration, error) {
+			atomic.AddInt32(&loads, 1)
+			return configuration, nil
+		}
+	})
+	defer stop()
+
+	configuration := receiveConfiguration(t, configurationChan)
+	require.Contains(t, configuration.Backends, "backend1")
+	assert.Equal(t, "http://172.17.0.1:80", configuration.Backends["backend1"].Servers["server1"].URL)
+
+	// The values file and a file of the directory are changed in the same debounce window
+	createFile(t, tempDir, "values.yml", "url: http://172.17.0.2:80\n")
+	createFile(t, rulesDir, "backend2.toml", backendWithURL("backend2", "http://172.17.0.3:80"))
+
+	configuration = receiveConfiguration(t, configurationChan)
+	assert.Equal(t, []string{"backend1", "backend2"}, backendNames(configuration))
+	assert.Equal(t, "http://172.17.0.2:80", configuration.Backends["backend1"].Servers["server1"].URL)
+
+	// Both changes have been coalesced into a single reload
+	select {
+	case <-configurationChan:
+		t.Fatal("Unexpected configuration sent after the coalesced reload")
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.EqualValues(t, 2, atomic.LoadInt32(&loads))
+}
+
+func TestProvideDirectoryAndNotWatch(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	tempTLSDir := createSubDir(t, tempDir, "tls")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 2
+
+	createRandomFile(t, tempDir, createFrontendConfiguration(expectedNumFrontends))
+	tempFile2 := createRandomFile(t, tempDir, createBackendConfiguration(expectedNumBackends))
+	createRandomFile(t, tempTLSDir, createTLSConfiguration(expectedNumTLSConf))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, withDirectory(tempDir))
+	defer stop()
+
+	// Wait for initial config message to be tested
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	assert.NoError(t, err)
+
+	// Now remove the backends file
+	expectedNumFrontends = 2
+	expectedNumBackends = 0
+	expectedNumTLSConf = 2
+	os.Remove(tempFile2.Name())
+
+	// Must fail because we don't watch the changes
+	err = waitForSignal(signal, 2*time.Second, "remove the backends file")
+	assert.Error(t, err)
+
+}
+
+func TestProvideYAMLFiles(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	createFile(t, tempDir, "frontends.yml", createYAMLFrontendConfiguration(expectedNumFrontends))
+	backendsFile := createFile(t, tempDir, "backends.yaml", createYAMLBackendConfiguration(expectedNumBackends))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, withDirectory(tempDir))
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "YAML directory")
+	assert.NoError(t, err)
+
+	expectedNumFrontends = 0
+	stopFile := provide(configurationChan, withFile(backendsFile))
+	defer stopFile()
+
+	err = waitForSignal(signal, 2*time.Second, "YAML single file")
+	assert.NoError(t, err)
+}
+
+func TestLoadFileConfigFromDirectoryOrder(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		mergeStrategy string
+		expectedURL   string
+	}{
+		{
+			desc:        "first definition is kept",
+			expectedURL: "http://172.17.0.1:80",
+		},
+		{
+			desc:          "last definition is kept",
+			mergeStrategy: mergeStrategyReplace,
+			expectedURL:   "http://172.17.0.2:80",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			// The sub-directory sorts before the file, but files of a directory are always loaded first
+			subDir := createSubDir(t, tempDir, "0-extra")
+			createFile(t, tempDir, "00-base.toml", createBackendConfiguration(1))
+			createFile(t, subDir, "zz-extra.toml", `
+[backends.backend1.servers.server1]
+url = "http://172.17.0.2:80"
+`)
+
+			pvd := &Provider{MergeStrategy: test.mergeStrategy}
+			configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+			require.NoError(t, err)
+
+			require.Contains(t, configuration.Backends, "backend1")
+			assert.Equal(t, test.expectedURL, configuration.Backends["backend1"].Servers["server1"].URL)
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectorySymlinks(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		followSymlinks      bool
+		expectedNumBackends int
+		expectedDirectories int
+	}{
+		{
+			desc:                "symbolic links not followed",
+			expectedNumBackends: 0,
+			expectedDirectories: 1,
+		},
+		{
+			desc:                "symbolic links followed",
+			followSymlinks:      true,
+			expectedNumBackends: 2,
+			expectedDirectories: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			releaseDir := createTempDir(t, "testrelease")
+			defer os.RemoveAll(releaseDir)
+
+			createFile(t, releaseDir, "backends.toml", createBackendConfiguration(2))
+			require.NoError(t, os.Symlink(releaseDir, filepath.Join(tempDir, "current")))
+			// A loop back to the root directory must not be followed endlessly
+			require.NoError(t, os.Symlink(tempDir, filepath.Join(releaseDir, "loop")))
+
+			pvd := &Provider{FollowSymlinks: test.followSymlinks}
+			configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+			require.NoError(t, err)
+			assert.Len(t, configuration.Backends, test.expectedNumBackends)
+
+			directories, err := pvd.getDirectoriesRecursively(tempDir, nil)
+			require.NoError(t, err)
+			assert.Len(t, directories, test.expectedDirectories)
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectorySkipInvalidFiles(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		skipInvalidFiles    bool
+		expectedError       bool
+		expectedNumBackends int
+	}{
+		{
+			desc:          "fail on invalid file",
+			expectedError: true,
+		},
+		{
+			desc:                "skip invalid file",
+			skipInvalidFiles:    true,
+			expectedNumBackends: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			createFile(t, tempDir, "00-invalid.toml", "[backends\n")
+			createFile(t, tempDir, "10-backends.toml", createBackendConfiguration(2))
+
+			pvd := &Provider{SkipInvalidFiles: test.skipInvalidFiles}
+			configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, configuration.Backends, test.expectedNumBackends)
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryConcurrency(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	subDir := createSubDir(t, tempDir, "extra")
+	for i := 0; i < 20; i++ {
+		// Each backend is defined by two files, with different URLs, so that the resolution depends on the load order
+		createFile(t, tempDir, fmt.Sprintf("file%02d.toml", i), backendWithURL(fmt.Sprintf("backend%d", i%10), fmt.Sprintf("http://172.17.0.%d:80", i)))
+		createFile(t, subDir, fmt.Sprintf("file%02d.toml", i), backendWithURL(fmt.Sprintf("backend%d", i), "http://172.17.1.1:80"))
+	}
+
+	expected, err := (&Provider{LoadConcurrency: 1, MergeStrategy: mergeStrategyReplace}).loadFileConfigFromDirectory(tempDir)
+	require.NoError(t, err)
+	require.Len(t, expected.Backends, 20)
+	assert.Equal(t, "http://172.17.1.1:80", expected.Backends["backend0"].Servers["server1"].URL)
+
+	for _, loadConcurrency := range []int{0, 4, 64} {
+		pvd := &Provider{LoadConcurrency: loadConcurrency, MergeStrategy: mergeStrategyReplace}
+		configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+		require.NoError(t, err)
+		assert.Equal(t, expected, configuration, "load concurrency %d", loadConcurrency)
+	}
+
+	// The error of the first invalid file in load order is returned, whichever is parsed first
+	createFile(t, tempDir, "file05.toml", "[backends\n")
+	createFile(t, subDir, "file01.toml", "[backends\n")
+	for _, loadConcurrency := range []int{1, 4, 64} {
+		pvd := &Provider{LoadConcurrency: loadConcurrency}
+		_, err := pvd.loadFileConfigFromDirectory(tempDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), filepath.Join(tempDir, "file05.toml"), "load concurrency %d", loadConcurrency)
+	}
+}
+
+func BenchmarkLoadFileConfigFromDirectory(b *testing.B) {
+	tempDir, _ := createBenchmarkDirectory(b, 2000)
+	defer os.RemoveAll(tempDir)
+
+	for _, loadConcurrency := range []int{1, 0} {
+		pvd := &Provider{LoadConcurrency: loadConcurrency}
+		b.Run(fmt.Sprintf("concurrency %d", loadConcurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := pvd.loadFileConfigFromDirectory(tempDir); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFileConfigInclude(t *testing.T) {
+	tempDir := createTempDir(t, "testinclude")
+	defer os.RemoveAll(tempDir)
+
+	commonDir := createSubDir(t, tempDir, "common")
+	createFile(t, commonDir, "backends.toml", createBackendConfiguration(2))
+	tempFile := createFile(t, tempDir, "rules.toml", createFrontendConfiguration(2), `
+[include]
+files = ["common/backends.toml"]
+`)
+
+	configuration, err := (&Provider{}).loadFileConfig(tempFile.Name())
+	require.NoError(t, err)
+
+	assert.Len(t, configuration.Frontends, 2)
+	assert.Len(t, configuration.Backends, 2)
+}
+
+func TestLoadFileConfigIncludeCycle(t *testing.T) {
+	tempDir := createTempDir(t, "testinclude")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "b.toml", `
+[include]
+files = ["a.toml"]
+`)
+	tempFile := createFile(t, tempDir, "a.toml", `
+[include]
+files = ["b.toml"]
+`)
+
+	_, err := (&Provider{}).loadFileConfig(tempFile.Name())
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "include cycle detected")
+	assert.Contains(t, err.Error(), filepath.Join(tempDir, "b.toml"))
+}
+
+func TestBuildConfigurationEmpty(t *testing.T) {
+	testCases := []struct {
+		desc                    string
+		allowEmptyConfiguration bool
+		expectedError           bool
+	}{
+		{
+			desc:                    "empty configuration allowed",
+			allowEmptyConfiguration: true,
+		},
+		{
+			desc:          "empty configuration rejected",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			createFile(t, tempDir, "empty.toml", "")
+
+			pvd := &Provider{Directory: tempDir, AllowEmptyConfiguration: test.allowEmptyConfiguration}
+			configuration, err := pvd.BuildConfiguration()
+			if test.expectedError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tempDir)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Empty(t, configuration.Frontends)
+		})
+	}
+}
+
+func TestBuildConfigurationRequireAtLeastOneFile(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		subDirectory          string
+		file                  string
+		filePattern           string
+		requireAtLeastOneFile bool
+		expectedError         string
+	}{
+		{
+			desc: "no file allowed",
+			file: "rules.txt",
+		},
+		{
+			desc:                  "no file rejected",
+			file:                  "rules.txt",
+			requireAtLeastOneFile: true,
+			expectedError:         "searched extensions: .toml, .tml, .yml, .yaml, .json, .tmpl",
+		},
+		{
+			desc:                  "no file matching the pattern rejected",
+			file:                  "rules.toml",
+			filePattern:           "traefik-*.toml",
+			requireAtLeastOneFile: true,
+			expectedError:         `matching the pattern "traefik-*.toml"`,
+		},
+		{
+			desc:                  "file in a sub-directory",
+			subDirectory:          "sub",
+			file:                  "rules.yml",
+			requireAtLeastOneFile: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			directory := tempDir
+			if test.subDirectory != "" {
+				directory = createSubDir(t, tempDir, test.subDirectory)
+			}
+			createFile(t, directory, test.file, "")
+
+			pvd := &Provider{
+				Directory:               tempDir,
+				FilePattern:             test.filePattern,
+				RequireAtLeastOneFile:   test.requireAtLeastOneFile,
+				AllowEmptyConfiguration: true,
+			}
+			_, err := pvd.BuildConfiguration()
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tempDir)
+				assert.Contains(t, err.Error(), test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryGzip(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createGzipFile(t, tempDir, "backends.toml.gz", createBackendConfiguration(2))
+	createGzipFile(t, tempDir, "frontends.yml.gz", createYAMLFrontendConfiguration(2))
+
+	configuration, err := (&Provider{}).loadFileConfigFromDirectory(tempDir)
+	require.NoError(t, err)
+
+	assert.Len(t, configuration.Backends, 2)
+	assert.Len(t, configuration.Frontends, 2)
+}
+
+func TestWatcherCallbackPartialWrite(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{
+		Directory:        tempDir,
+		ProviderName:     "file",
+		ReloadRetries:    1,
+		ReloadRetryDelay: flaeg.Duration(200 * time.Millisecond),
+	}
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	err := pvd.Provide(configurationChan, pool, nil)
+	require.NoError(t, err)
+	<-configurationChan
+
+	// The change is notified while the file is still being written
+	content := createBackendConfiguration(3)
+	tempFile := createFile(t, tempDir, "backends.toml", content[:len(content)/2])
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		createFile(t, tempDir, "backends.toml", content)
+	}()
+
+	pvd.watcherCallback(configurationChan, fsnotify.Event{Name: tempFile.Name(), Op: fsnotify.Write}, nil)
+
+	require.Len(t, configurationChan, 1)
+	msg := <-configurationChan
+	assert.Len(t, msg.Configuration.Backends, 3)
+}
+
+func TestWatcherCallbackRetries(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		reloadRetries int
+		expectedSent  int
+	}{
+		{
+			desc:         "no retry",
+			expectedSent: 0,
+		},
+		{
+			desc:          "retries exhausted before the file is fixed",
+			reloadRetries: 1,
+			expectedSent:  0,
+		},
+		{
+			desc:          "file fixed between two retries",
+			reloadRetries: 3,
+			expectedSent:  1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			tempFile := createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+			configurationChan := make(chan types.ConfigMessage, 10)
+			pvd := &Provider{
+				Directory:        tempDir,
+				ReloadRetries:    test.reloadRetries,
+				ReloadRetryDelay: flaeg.Duration(100 * time.Millisecond),
+			}
+			_, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			// The file can not be read until it is fixed, after the first retry and before the second one, 300ms later
+			createFile(t, tempDir, "backends.toml", "[backends\n")
+			fixed := make(chan struct{})
+			go func() {
+				defer close(fixed)
+				time.Sleep(200 * time.Millisecond)
+				createFile(t, tempDir, "backends.toml", createBackendConfiguration(3))
+			}()
+
+			pvd.watcherCallback(configurationChan, fsnotify.Event{Name: tempFile.Name(), Op: fsnotify.Write}, nil)
+			<-fixed
+
+			assert.Len(t, configurationChan, test.expectedSent)
+		})
+	}
+}
+
+func TestWatcherCallbackRetryStopped(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	tempFile := createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{
+		Directory:        tempDir,
+		ReloadRetries:    3,
+		ReloadRetryDelay: flaeg.Duration(time.Hour),
+	}
+	_, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	createFile(t, tempDir, "backends.toml", "[backends\n")
+
+	stop := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pvd.watcherCallback(configurationChan, fsnotify.Event{Name: tempFile.Name(), Op: fsnotify.Write}, stop)
+	}()
+
+	// The other reloads are not blocked while waiting for the next retry
+	reloaded := make(chan struct{})
+	go func() {
+		defer close(reloaded)
+		time.Sleep(50 * time.Millisecond)
+		pvd.Reload(configurationChan)
+	}()
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("reload blocked by the retry of the watcher callback")
+	}
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher callback not stopped")
+	}
+	assert.Len(t, configurationChan, 0)
+}
+
+func TestReload(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{Directory: tempDir, ProviderName: "file"}
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	err := pvd.Provide(configurationChan, pool, nil)
+	require.NoError(t, err)
+
+	msg := <-configurationChan
+	assert.Len(t, msg.Configuration.Backends, 2)
+
+	// The unchanged configuration is not sent again
+	pvd.Reload(configurationChan)
+	assert.Len(t, configurationChan, 0)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(3))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pvd.Reload(configurationChan)
+		}()
+	}
+	wg.Wait()
+
+	// Concurrent reloads of the same configuration send it once
+	require.Len(t, configurationChan, 1)
+	msg = <-configurationChan
+	assert.Len(t, msg.Configuration.Backends, 3)
+}
+
+func TestForceReload(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	pvd := &Provider{Directory: tempDir, ProviderName: "file"}
+	err := pvd.ForceReload()
+	require.Error(t, err)
+	assert.Equal(t, "the file provider is not started", err.Error())
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	err = pvd.Provide(configurationChan, pool, nil)
+	require.NoError(t, err)
+
+	msg := <-configurationChan
+	assert.Len(t, msg.Configuration.Backends, 2)
+
+	// The unchanged configuration is sent again
+	require.NoError(t, pvd.ForceReload())
+	require.Len(t, configurationChan, 1)
+	msg = <-configurationChan
+	assert.Len(t, msg.Configuration.Backends, 2)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(3))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, pvd.ForceReload())
+		}()
+	}
+	wg.Wait()
+
+	// Concurrent forced reloads each send the latest configuration
+	require.Len(t, configurationChan, 3)
+	for i := 0; i < 3; i++ {
+		msg = <-configurationChan
+		assert.Len(t, msg.Configuration.Backends, 3)
+	}
+
+	// A configuration which can not be loaded is not sent
+	createFile(t, tempDir, "backends.toml", "[backends")
+	assert.Error(t, pvd.ForceReload())
+	assert.Len(t, configurationChan, 0)
+}
+
+func TestProvideDirectoryAndWatchTriggerFile(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+	createFile(t, tempDir, ".version", "1")
+
+	expectedNumFrontends := 0
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.Directory = tempDir
+		pvd.TriggerFile = filepath.Join(tempDir, ".version")
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	require.NoError(t, err)
+
+	expectedNumBackends = 3
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(3))
+
+	// The changes of the configuration files are ignored
+	err = waitForSignal(signal, 200*time.Millisecond, "config without trigger")
+	require.Error(t, err)
+
+	createFile(t, tempDir, ".version", "2")
+
+	err = waitForSignal(signal, 2*time.Second, "config after trigger")
+	assert.NoError(t, err)
+}
+
+func TestProcessEventsWatcherFailure(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := newWatcher([]string{tempDir})
+	require.NoError(t, err)
+	// Closing the watcher closes its channels, as a failing watcher does
+	watcher.Close()
+
+	pvd := &Provider{Directory: tempDir}
+	failed := pvd.processEvents(watcher, nil, make(chan bool), nil, func(chan<- types.ConfigMessage, fsnotify.Event, chan bool) {})
+	assert.True(t, failed)
+}
+
+func TestProcessEventsWatcherError(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := newWatcher([]string{tempDir})
+	require.NoError(t, err)
+
+	events := make(chan fsnotify.Event, 1)
+	stop := make(chan bool)
+	defer close(stop)
+
+	pvd := &Provider{Directory: tempDir}
+	go pvd.processEvents(watcher, nil, stop, nil, func(_ chan<- types.ConfigMessage, evt fsnotify.Event, _ chan bool) {
+		events <- evt
+	})
+
+	watcher.Errors <- errors.New("queue overflow")
+
+	select {
+	case evt := <-events:
+		// An event without name reloads the whole configuration
+		assert.Equal(t, fsnotify.Event{}, evt)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the reload after the watcher error")
+	}
+}
+
+func TestProcessEventsRepeatedWatcherErrors(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := newWatcher([]string{tempDir})
+	require.NoError(t, err)
+
+	stop := make(chan bool)
+	defer close(stop)
+
+	failed := make(chan bool, 1)
+	pvd := &Provider{Directory: tempDir, DebounceDuration: flaeg.Duration(time.Minute)}
+	go func() {
+		failed <- pvd.processEvents(watcher, nil, stop, nil, func(chan<- types.ConfigMessage, fsnotify.Event, chan bool) {})
+	}()
+
+	// An event between the errors shows that the watcher still works
+	watcher.Errors <- errors.New("read error")
+	watcher.Events <- fsnotify.Event{Name: filepath.Join(tempDir, "rules.toml"), Op: fsnotify.Write}
+	for i := 1; i < maxWatcherErrors; i++ {
+		watcher.Errors <- errors.New("read error")
+	}
+
+	select {
+	case <-failed:
+		t.Fatal("Watcher failed before reporting too many errors in a row")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	watcher.Errors <- errors.New("read error")
+
+	select {
+	case result := <-failed:
+		assert.True(t, result)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the watcher to fail")
+	}
+}
+
+func TestProvideDirectoryAndWatchRootRemoved(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	rulesDir := createSubDir(t, tempDir, "rules")
+	createFile(t, rulesDir, "backends.toml", createBackendConfiguration(1))
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	stop := provide(configurationChan, watch, withDirectory(rulesDir), func(pvd *Provider) {
+		pvd.WatcherRestartMinDelay = flaeg.Duration(50 * time.Millisecond)
+		pvd.WatcherRestartMaxDelay = flaeg.Duration(50 * time.Millisecond)
+	})
+	defer stop()
+	assert.Len(t, receiveConfiguration(t, configurationChan).Backends, 1)
+
+	require.NoError(t, os.RemoveAll(rulesDir))
+	createSubDir(t, tempDir, "rules")
+	createFile(t, rulesDir, "backends.toml", createBackendConfiguration(2))
+
+	// The watcher is restarted on the new directory, whose changes are watched
+	timeout := time.After(5 * time.Second)
+	for backends := 2; backends <= 3; backends++ {
+		for {
+			var configuration *types.Configuration
+			select {
+			case msg := <-configurationChan:
+				configuration = msg.Configuration
+			case <-timeout:
+				t.Fatalf("Timed out waiting for the configuration with %d backends", backends)
+			}
+			if len(configuration.Backends) == backends {
+				break
+			}
+		}
+		createFile(t, rulesDir, "backends.toml", createBackendConfiguration(backends+1))
+	}
+}
+
+func TestProvideDirectoryAndWatchFlood(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	configurationChan := make(chan types.ConfigMessage, 100)
+	stop := provide(configurationChan, watch, allowEmptyConfiguration, withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.Directory = tempDir
+	})
+	defer stop()
+
+	numFiles := 200
+	for i := 0; i < numFiles; i++ {
+		createFile(t, tempDir, fmt.Sprintf("backend%d.toml", i), backendWithURL(fmt.Sprintf("backend%d", i), "http://172.17.0.1:80"))
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-configurationChan:
+			if len(msg.Configuration.Backends) == numFiles {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for the configuration of all the files")
+		}
+	}
+}
+
+func TestProcessEventsMinReloadInterval(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	watcher, err := newWatcher([]string{tempDir})
+	require.NoError(t, err)
+
+	type reload struct {
+		event fsnotify.Event
+		time  time.Time
+	}
+	reloads := make(chan reload, 10)
+	stop := make(chan bool)
+	defer close(stop)
+
+	minReloadInterval := 100 * time.Millisecond
+	pvd := &Provider{Directory: tempDir, MinReloadInterval: flaeg.Duration(minReloadInterval)}
+	go pvd.processEvents(watcher, nil, stop, nil, func(_ chan<- types.ConfigMessage, evt fsnotify.Event, _ chan bool) {
+		reloads <- reload{event: evt, time: time.Now()}
+	})
+
+	first := fsnotify.Event{Name: filepath.Join(tempDir, "a.toml"), Op: fsnotify.Write}
+	watcher.Events <- first
+	// The following changes are rate limited, and coalesced
+	watcher.Events <- fsnotify.Event{Name: filepath.Join(tempDir, "b.toml"), Op: fsnotify.Write}
+	watcher.Events <- fsnotify.Event{Name: filepath.Join(tempDir, "c.toml"), Op: fsnotify.Write}
+
+	var firstReload, lastReload reload
+	select {
+	case firstReload = <-reloads:
+		assert.Equal(t, first, firstReload.event)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the first reload")
+	}
+
+	select {
+	case lastReload = <-reloads:
+		assert.Equal(t, fsnotify.Event{}, lastReload.event)
+		assert.True(t, lastReload.time.Sub(firstReload.time) >= minReloadInterval, "reloaded after %s", lastReload.time.Sub(firstReload.time))
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the rate limited reload")
+	}
+
+	select {
+	case extra := <-reloads:
+		t.Fatalf("Unexpected reload after %s", extra.event)
+	case <-time.After(2 * minReloadInterval):
+	}
+}
+
+func TestRestartWatcher(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+	createSubDir(t, tempDir, "sub")
+
+	pvd := &Provider{Directory: tempDir, WatcherRestartMinDelay: flaeg.Duration(time.Millisecond)}
+	watcher, roots := pvd.restartWatcher(make(chan bool))
+	require.NotNil(t, watcher)
+	defer watcher.Close()
+	assert.Equal(t, []string{tempDir}, roots)
+
+	createFile(t, filepath.Join(tempDir, "sub"), "rules.toml")
+
+	select {
+	case evt := <-watcher.Events:
+		assert.Equal(t, filepath.Join(tempDir, "sub", "rules.toml"), evt.Name)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for an event of the restarted watcher")
+	}
+}
+
+func TestRestartWatcherStopped(t *testing.T) {
+	pvd := &Provider{Directory: "/missing", WatcherRestartMinDelay: flaeg.Duration(time.Millisecond)}
+
+	stop := make(chan bool)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(stop)
+	}()
+
+	watcher, _ := pvd.restartWatcher(stop)
+	assert.Nil(t, watcher)
+}
+
+func TestWatcherBackOff(t *testing.T) {
+	pvd := &Provider{
+		WatcherRestartMinDelay: flaeg.Duration(time.Second),
+		WatcherRestartMaxDelay: flaeg.Duration(5 * time.Second),
+	}
+
+	backOff := pvd.watcherBackOff()
+	assert.Equal(t, time.Second, backOff.InitialInterval)
+	assert.Equal(t, 5*time.Second, backOff.MaxInterval)
+	assert.Equal(t, time.Duration(0), backOff.MaxElapsedTime)
+}
+
+func TestLoadFileConfigFromDirectoryDuplicateTLS(t *testing.T) {
 	tempDir := createTempDir(t, "testdir")
 	defer os.RemoveAll(tempDir)
 
-	expectedNumFrontends := 2
-	expectedNumBackends := 2
-	expectedNumTLSConf := 2
+	createFile(t, tempDir, "a.toml", createTLSConfiguration(2))
+	createFile(t, tempDir, "b.toml", createTLSConfiguration(1), `[[TLSConfiguration]]
+	EntryPoints = ["http", "https"]
+	[TLSConfiguration.Certificate]
+	CertFile = "integration/fixtures/https/snitest1.com.cert"
+	KeyFile = "integration/fixtures/https/snitest1.com.key"
+`)
 
-	tempFile1 := createRandomFile(t, tempDir, createFrontendConfiguration(expectedNumFrontends))
-	tempFile2 := createRandomFile(t, tempDir, createBackendConfiguration(expectedNumBackends))
-	tempFile3 := createRandomFile(t, tempDir, createTLSConfiguration(expectedNumTLSConf))
+	configuration, err := (&Provider{}).loadFileConfigFromDirectory(tempDir)
+	require.NoError(t, err)
 
-	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+	require.Len(t, configuration.TLSConfiguration, 3)
+	assert.Equal(t, []string{"https"}, configuration.TLSConfiguration[0].EntryPoints)
+	assert.Equal(t, []string{"https"}, configuration.TLSConfiguration[1].EntryPoints)
+	assert.Equal(t, []string{"http", "https"}, configuration.TLSConfiguration[2].EntryPoints)
+}
 
-	provide(configurationChan, watch, withDirectory(tempDir))
+func TestMaxDepth(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		maxDepth            int
+		expectedNumBackends int
+		expectedDirectories int
+	}{
+		{desc: "unlimited", maxDepth: 0, expectedNumBackends: 3, expectedDirectories: 3},
+		{desc: "directory only", maxDepth: 1, expectedNumBackends: 1, expectedDirectories: 1},
+		{desc: "first level of sub-directories", maxDepth: 2, expectedNumBackends: 2, expectedDirectories: 2},
+	}
 
-	// Wait for initial config message to be tested
-	err := waitForSignal(signal, 2*time.Second, "initial config")
-	assert.NoError(t, err)
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
 
-	// Now remove the backends file
-	expectedNumFrontends = 2
-	expectedNumBackends = 0
-	expectedNumTLSConf = 2
-	os.Remove(tempFile2.Name())
-	err = waitForSignal(signal, 2*time.Second, "remove the backends file")
-	assert.NoError(t, err)
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
 
-	// Now remove the frontends file
-	expectedNumFrontends = 0
-	expectedNumBackends = 0
-	expectedNumTLSConf = 2
-	os.Remove(tempFile1.Name())
-	err = waitForSignal(signal, 2*time.Second, "remove the frontends file")
-	assert.NoError(t, err)
+			subDir := createSubDir(t, tempDir, "sub")
+			subSubDir := createSubDir(t, subDir, "sub")
+			createFile(t, tempDir, "a.toml", backendWithURL("backend1", "http://172.17.0.1:80"))
+			createFile(t, subDir, "b.toml", backendWithURL("backend2", "http://172.17.0.1:80"))
+			createFile(t, subSubDir, "c.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
 
-	// Now remove the TLS configuration file
-	expectedNumFrontends = 0
-	expectedNumBackends = 0
-	expectedNumTLSConf = 0
-	os.Remove(tempFile3.Name())
-	err = waitForSignal(signal, 2*time.Second, "remove the TLS configuration file")
-	assert.NoError(t, err)
+			pvd := &Provider{Directory: tempDir, MaxDepth: test.maxDepth}
+
+			configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+			require.NoError(t, err)
+			assert.Len(t, configuration.Backends, test.expectedNumBackends)
+
+			directories, err := pvd.getDirectoriesRecursively(tempDir, nil)
+			require.NoError(t, err)
+			assert.Len(t, directories, test.expectedDirectories)
+		})
+	}
 }
 
-func TestProvideDirectoryAndNotWatch(t *testing.T) {
-	tempDir := createTempDir(t, "testdir")
-	tempTLSDir := createSubDir(t, tempDir, "tls")
-	defer os.RemoveAll(tempDir)
+func TestIsSelectedFile(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		filePattern        string
+		includeHiddenFiles bool
+		filename           string
+		expected           bool
+	}{
+		{desc: "no pattern, supported extension", filename: "/etc/traefik/rules.toml", expected: true},
+		{desc: "no pattern, unsupported extension", filename: "/etc/traefik/rules.txt", expected: false},
+		{desc: "matching pattern", filePattern: "traefik-*.toml", filename: "/etc/traefik/traefik-rules.toml", expected: true},
+		{desc: "not matching pattern", filePattern: "traefik-*.toml", filename: "/etc/traefik/rules.toml", expected: false},
+		{desc: "matching pattern, unsupported extension", filePattern: "traefik-*", filename: "/etc/traefik/traefik-rules.txt", expected: false},
+		{desc: "no pattern, compressed supported extension", filename: "/etc/traefik/rules.toml.gz", expected: true},
+		{desc: "no pattern, compressed unsupported extension", filename: "/etc/traefik/rules.txt.gz", expected: false},
+		{desc: "hidden file", filename: "/etc/traefik/.rules.toml", expected: false},
+		{desc: "hidden file included", includeHiddenFiles: true, filename: "/etc/traefik/.rules.toml", expected: true},
+		{desc: "file in hidden directory", filename: "/etc/.traefik/rules.toml", expected: true},
+	}
 
-	expectedNumFrontends := 2
-	expectedNumBackends := 2
-	expectedNumTLSConf := 2
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
 
-	createRandomFile(t, tempDir, createFrontendConfiguration(expectedNumFrontends))
-	tempFile2 := createRandomFile(t, tempDir, createBackendConfiguration(expectedNumBackends))
-	createRandomFile(t, tempTLSDir, createTLSConfiguration(expectedNumTLSConf))
+			pvd := &Provider{FilePattern: test.filePattern, IncludeHiddenFiles: test.includeHiddenFiles}
+			assert.Equal(t, test.expected, pvd.isSelectedFile(test.filename))
+		})
+	}
+}
 
-	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+func TestBuildConfigurationFilePattern(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
 
-	provide(configurationChan, withDirectory(tempDir))
+	createFile(t, tempDir, "traefik-frontends.toml", createFrontendConfiguration(2))
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
 
-	// Wait for initial config message to be tested
-	err := waitForSignal(signal, 2*time.Second, "initial config")
-	assert.NoError(t, err)
+	pvd := &Provider{Directory: tempDir, FilePattern: "traefik-*.toml"}
+	configuration, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
 
-	// Now remove the backends file
-	expectedNumFrontends = 2
-	expectedNumBackends = 0
-	expectedNumTLSConf = 2
-	os.Remove(tempFile2.Name())
+	assert.Len(t, configuration.Frontends, 2)
+	assert.Len(t, configuration.Backends, 0)
 
-	// Must fail because we don't watch the changes
-	err = waitForSignal(signal, 2*time.Second, "remove the backends file")
+	pvd.FilePattern = "["
+	_, err = pvd.BuildConfiguration()
 	assert.Error(t, err)
-
 }
 
 func createConfigurationRoutine(t *testing.T, expectedNumFrontends *int, expectedNumBackends *int, expectedNumTLSConfigurations *int) (chan types.ConfigMessage, chan interface{}) {
@@ -198,26 +1547,45 @@ func waitForSignal(signal chan interface{}, timeout time.Duration, caseName stri
 	return nil
 }
 
-func provide(configurationChan chan types.ConfigMessage, builders ...func(p *Provider)) {
+// provide starts a provider built with the builders, returning the function stopping it
+// along with the goroutines it started, such as the file watcher.
+func provide(configurationChan chan types.ConfigMessage, builders ...func(p *Provider)) func() {
 	pvd := &Provider{}
 
 	for _, builder := range builders {
 		builder(pvd)
 	}
 
-	pvd.Provide(configurationChan, safe.NewPool(context.Background()), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := safe.NewPool(ctx)
+	pvd.Provide(configurationChan, pool, nil)
+
+	return func() {
+		pool.Stop()
+		cancel()
+	}
 }
 
 func watch(pvd *Provider) {
 	pvd.Watch = true
 }
 
+func allowEmptyConfiguration(pvd *Provider) {
+	pvd.AllowEmptyConfiguration = true
+}
+
 func withDirectory(name string) func(*Provider) {
 	return func(pvd *Provider) {
 		pvd.Directory = name
 	}
 }
 
+func withDebounce(duration time.Duration) func(*Provider) {
+	return func(pvd *Provider) {
+		pvd.DebounceDuration = flaeg.Duration(duration)
+	}
+}
+
 func withFile(tempFile *os.File) func(*Provider) {
 	return func(p *Provider) {
 		p.Filename = tempFile.Name()
@@ -229,6 +1597,20 @@ func createRandomFile(t *testing.T, tempDir string, contents ...string) *os.File
 	return createFile(t, tempDir, fmt.Sprintf("temp%d.toml", time.Now().UnixNano()), contents...)
 }
 
+// createGzipFile Helper
+func createGzipFile(t *testing.T, tempDir string, name string, content string) {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	err = ioutil.WriteFile(path.Join(tempDir, name), buffer.Bytes(), 0644)
+	require.NoError(t, err)
+}
+
 // createFile Helper
 func createFile(t *testing.T, tempDir string, name string, contents ...string) *os.File {
 	t.Helper()
@@ -254,6 +1636,16 @@ func createFile(t *testing.T, tempDir string, name string, contents ...string) *
 	return tempFile
 }
 
+// replaceFile writes the contents to a hidden file renamed over the file name of the directory,
+// so that the watcher never reads the file partially written.
+func replaceFile(t *testing.T, tempDir string, name string, contents ...string) {
+	t.Helper()
+	tempFile := createFile(t, tempDir, "."+name+".tmp", contents...)
+	if err := os.Rename(tempFile.Name(), filepath.Join(tempDir, name)); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // createTempDir Helper
 func createTempDir(t *testing.T, dir string) string {
 	t.Helper()
@@ -310,3 +1702,439 @@ func createTLSConfiguration(n int) string {
 	}
 	return conf
 }
+
+// createYAMLFrontendConfiguration Helper
+func createYAMLFrontendConfiguration(n int) string {
+	conf := "frontends:\n"
+	for i := 1; i <= n; i++ {
+		conf += fmt.Sprintf(`  frontend%[1]d:
+    backend: backend%[1]d
+`, i)
+	}
+	return conf
+}
+
+// createYAMLBackendConfiguration Helper
+func createYAMLBackendConfiguration(n int) string {
+	conf := "backends:\n"
+	for i := 1; i <= n; i++ {
+		conf += fmt.Sprintf(`  backend%[1]d:
+    servers:
+      server1:
+        url: http://172.17.0.%[1]d:80
+`, i)
+	}
+	return conf
+}
+
+// collectingRegistry is a metrics registry collecting the configuration reloads.
+type collectingRegistry struct {
+	metrics.Registry
+	configReloadsCounter          *collectingCounter
+	configStaleReloadsGauge       *collectingGauge
+	configReloadDurationHistogram *collectingHistogram
+}
+
+func (r *collectingRegistry) ConfigReloadsCounter() gokitmetrics.Counter {
+	return r.configReloadsCounter
+}
+
+func (r *collectingRegistry) ConfigStaleReloadsGauge() gokitmetrics.Gauge {
+	if r.configStaleReloadsGauge == nil {
+		return r.Registry.ConfigStaleReloadsGauge()
+	}
+	return r.configStaleReloadsGauge
+}
+
+func (r *collectingRegistry) ConfigReloadDurationHistogram() gokitmetrics.Histogram {
+	if r.configReloadDurationHistogram == nil {
+		return r.Registry.ConfigReloadDurationHistogram()
+	}
+	return r.configReloadDurationHistogram
+}
+
+// collectingHistogram sends the label values of its observations on a channel, as they are observed from the provider goroutines.
+type collectingHistogram struct {
+	labelValues []string
+	values      chan []string
+}
+
+func (h *collectingHistogram) With(labelValues ...string) gokitmetrics.Histogram {
+	return &collectingHistogram{labelValues: labelValues, values: h.values}
+}
+
+func (h *collectingHistogram) Observe(value float64) {
+	h.values <- h.labelValues
+}
+
+func (h *collectingHistogram) waitForLabelValues(t *testing.T) []string {
+	t.Helper()
+
+	select {
+	case labelValues := <-h.values:
+		return labelValues
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for a histogram observation")
+		return nil
+	}
+}
+
+// collectingGauge sends the values it is set to on a channel, as they are set from the provider goroutines.
+type collectingGauge struct {
+	values chan float64
+}
+
+func (g *collectingGauge) With(labelValues ...string) gokitmetrics.Gauge {
+	return g
+}
+
+func (g *collectingGauge) Set(value float64) {
+	g.values <- value
+}
+
+func (g *collectingGauge) waitForValue(t *testing.T) float64 {
+	t.Helper()
+
+	select {
+	case value := <-g.values:
+		return value
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for a gauge value")
+		return 0
+	}
+}
+
+// collectingCounter records its value and the label values of its last addition, as they are added from the provider goroutines.
+type collectingCounter struct {
+	lock            sync.Mutex
+	counterValue    float64
+	lastLabelValues []string
+}
+
+func (c *collectingCounter) With(labelValues ...string) gokitmetrics.Counter {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.lastLabelValues = labelValues
+	return c
+}
+
+func (c *collectingCounter) Add(delta float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.counterValue += delta
+}
+
+func (c *collectingCounter) labelValues() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.lastLabelValues
+}
+
+func (c *collectingCounter) value() float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.counterValue
+}
+
+func TestProvideSingleFileAndWatchUnchanged(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	tempFile := createFile(t, tempDir, "simple.toml", createFrontendConfiguration(2), createBackendConfiguration(2))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, withFile(tempFile), withDebounce(100*time.Millisecond))
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	require.NoError(t, err)
+
+	// Rewriting the same content does not send the configuration again
+	createFile(t, tempDir, "simple.toml", createFrontendConfiguration(2), createBackendConfiguration(2))
+
+	err = waitForSignal(signal, 200*time.Millisecond, "unchanged config")
+	assert.Error(t, err)
+
+	expectedNumFrontends = 1
+	expectedNumBackends = 1
+	createFile(t, tempDir, "simple.toml", createFrontendConfiguration(1), createBackendConfiguration(1))
+
+	err = waitForSignal(signal, 2*time.Second, "changed config")
+	assert.NoError(t, err)
+}
+
+func TestConfigurationHash(t *testing.T) {
+	fromTOML, err := decodeFileContent([]byte(createFrontendConfiguration(2)+createBackendConfiguration(2)), formatTOML)
+	require.NoError(t, err)
+	fromYAML, err := decodeFileContent([]byte(createYAMLFrontendConfiguration(2)+createYAMLBackendConfiguration(2)), formatYAML)
+	require.NoError(t, err)
+	other, err := decodeFileContent([]byte(createFrontendConfiguration(1)+createBackendConfiguration(1)), formatTOML)
+	require.NoError(t, err)
+
+	hash, err := configurationHash(&fromTOML.Configuration)
+	require.NoError(t, err)
+
+	sameHash, err := configurationHash(&fromYAML.Configuration)
+	require.NoError(t, err)
+	assert.Equal(t, hash, sameHash)
+
+	otherHash, err := configurationHash(&other.Configuration)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+}
+
+func TestProvideSingleFileDirectWatch(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	tempFile := createFile(t, tempDir, "config.toml", createFrontendConfiguration(2), createBackendConfiguration(2))
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, withFile(tempFile), withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.DirectWatch = true
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	require.NoError(t, err)
+
+	// Changes of the other files of the directory are not watched
+	createFile(t, tempDir, "other.toml", createFrontendConfiguration(3))
+
+	err = waitForSignal(signal, 200*time.Millisecond, "other file")
+	assert.Error(t, err)
+
+	// The file is watched again after each replacement
+	for i := 1; i <= 2; i++ {
+		expectedNumFrontends = i
+		expectedNumBackends = i
+
+		tempFile2 := createFile(t, tempDir, "config.toml.tmp", createFrontendConfiguration(i), createBackendConfiguration(i))
+		err = os.Rename(tempFile2.Name(), tempFile.Name())
+		require.NoError(t, err)
+
+		err = waitForSignal(signal, 2*time.Second, fmt.Sprintf("file renamed into place %d", i))
+		require.NoError(t, err)
+	}
+
+	// The directory is watched until the removed file is created again,
+	// the file being written again until the removal has been processed and the directory is watched
+	err = os.Remove(tempFile.Name())
+	require.NoError(t, err)
+
+	expectedNumFrontends = 3
+	expectedNumBackends = 3
+	for attempt := 1; ; attempt++ {
+		createFile(t, tempDir, "config.toml", createFrontendConfiguration(3), createBackendConfiguration(3))
+
+		err = waitForSignal(signal, 300*time.Millisecond, "file created again")
+		if err == nil {
+			break
+		}
+		require.True(t, attempt < 10, "file created again not loaded")
+	}
+
+	expectedNumFrontends = 1
+	expectedNumBackends = 1
+	createFile(t, tempDir, "config.toml", createFrontendConfiguration(1), createBackendConfiguration(1))
+
+	err = waitForSignal(signal, 2*time.Second, "file changed")
+	assert.NoError(t, err)
+}
+
+func TestLoadFileConfigFromDirectoryUnreadable(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	unreadableDir := createSubDir(t, tempDir, "unreadable")
+	readableDir := createSubDir(t, tempDir, "readable")
+	createFile(t, tempDir, "a.toml", backendWithURL("backend1", "http://172.17.0.1:80"))
+	createFile(t, unreadableDir, "b.toml", backendWithURL("backend2", "http://172.17.0.1:80"))
+	createFile(t, readableDir, "c.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
+
+	readDir := func(directory string) ([]os.FileInfo, error) {
+		if directory == unreadableDir {
+			return nil, &os.PathError{Op: "open", Path: directory, Err: os.ErrPermission}
+		}
+		return ioutil.ReadDir(directory)
+	}
+
+	pvd := &Provider{Directory: tempDir, readDir: readDir}
+	configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+	require.NoError(t, err)
+	assert.Len(t, configuration.Backends, 2)
+	assert.Contains(t, configuration.Backends, "backend1")
+	assert.Contains(t, configuration.Backends, "backend3")
+
+	directories, err := pvd.getDirectoriesRecursively(tempDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{tempDir, readableDir}, directories)
+
+	// The configured directory itself must be readable
+	pvd = &Provider{Directory: unreadableDir, readDir: readDir}
+	_, err = pvd.loadFileConfigFromDirectory(unreadableDir)
+	assert.Error(t, err)
+}
+
+func TestBuildConfigurationTransform(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		transform     func(*types.Configuration) (*types.Configuration, error)
+		expectedURL   string
+		expectedError string
+	}{
+		{
+			desc: "rewritten servers",
+			transform: func(configuration *types.Configuration) (*types.Configuration, error) {
+				transformed := &types.Configuration{
+					Frontends: configuration.Frontends,
+					Backends:  make(map[string]*types.Backend, len(configuration.Backends)),
+				}
+				for backendName, backend := range configuration.Backends {
+					rewritten := *backend
+					rewritten.Servers = make(map[string]types.Server, len(backend.Servers))
+					for serverName, server := range backend.Servers {
+						server.URL = strings.Replace(server.URL, "http://", "https://", 1)
+						rewritten.Servers[serverName] = server
+					}
+					transformed.Backends[backendName] = &rewritten
+				}
+				return transformed, nil
+			},
+			expectedURL: "https://172.17.0.1:80",
+		},
+		{
+			desc: "error",
+			transform: func(*types.Configuration) (*types.Configuration, error) {
+				return nil, errors.New("no default middleware")
+			},
+			expectedError: "unable to transform the configuration loaded from file",
+		},
+		{
+			desc: "no configuration returned",
+			transform: func(*types.Configuration) (*types.Configuration, error) {
+				return nil, nil
+			},
+			expectedError: "no configuration returned",
+		},
+		{
+			desc: "invalid configuration returned",
+			transform: func(configuration *types.Configuration) (*types.Configuration, error) {
+				return &types.Configuration{}, nil
+			},
+			expectedError: "empty configuration",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testfile")
+			defer os.RemoveAll(tempDir)
+
+			tempFile := createFile(t, tempDir, "simple.toml", createFrontendConfiguration(1), createBackendConfiguration(1))
+
+			pvd := &Provider{Transform: test.transform}
+			pvd.Filename = tempFile.Name()
+			configuration, err := pvd.BuildConfiguration()
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedURL, configuration.Backends["backend1"].Servers["server1"].URL)
+		})
+	}
+}
+
+func TestProvideTransformError(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	tempFile := createFile(t, tempDir, "simple.toml", createFrontendConfiguration(2), createBackendConfiguration(2))
+
+	expectedNumFrontends := 1
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	// The frontends named "rejected" fail the transform
+	stop := provide(configurationChan, watch, withFile(tempFile), withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.Transform = func(configuration *types.Configuration) (*types.Configuration, error) {
+			if _, ok := configuration.Frontends["rejected"]; ok {
+				return nil, errors.New("rejected frontend")
+			}
+			transformed := *configuration
+			transformed.Frontends = map[string]*types.Frontend{"frontend1": configuration.Frontends["frontend1"]}
+			return &transformed, nil
+		}
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	require.NoError(t, err)
+
+	createFile(t, tempDir, "simple.toml", createFrontendConfiguration(2), createBackendConfiguration(3), `
+[frontends.rejected]
+backend = "backend1"
+`)
+	err = waitForSignal(signal, 200*time.Millisecond, "rejected config")
+	assert.Error(t, err)
+
+	expectedNumBackends = 3
+	createFile(t, tempDir, "simple.toml", createFrontendConfiguration(2), createBackendConfiguration(3))
+	err = waitForSignal(signal, 2*time.Second, "transformed config")
+	assert.NoError(t, err)
+}
+
+func TestProvideOnConfiguration(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	tempFile := createFile(t, tempDir, "simple.toml", createFrontendConfiguration(2), createBackendConfiguration(2))
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 3
+	expectedNumTLSConf := 0
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	var calls int32
+	stop := provide(configurationChan, watch, withFile(tempFile), withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.OnConfiguration = func(configuration *types.Configuration) {
+			atomic.AddInt32(&calls, 1)
+			configuration.Backends["added"] = &types.Backend{}
+		}
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	expectedNumBackends = 2
+	createFile(t, tempDir, "simple.toml", createFrontendConfiguration(2), createBackendConfiguration(1))
+
+	err = waitForSignal(signal, 2*time.Second, "reloaded config")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
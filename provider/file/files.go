@@ -0,0 +1,64 @@
+package file
+
+import (
+	"path/filepath"
+)
+
+// hasFiles returns true if the configuration is loaded from the list of Files, the directories taking precedence.
+func (p *Provider) hasFiles() bool {
+	return !p.hasDirectories() && len(p.resolved().files) > 0
+}
+
+// readsRemote returns true if the configuration is fetched from the RemoteURL,
+// the directories, the list of Files and the Archive taking precedence.
+func (p *Provider) readsRemote() bool {
+	return !p.hasDirectories() && !p.hasFiles() && p.Archive == "" && p.RemoteURL != ""
+}
+
+// readsSingleFile returns true if the configuration is loaded from the Filename.
+func (p *Provider) readsSingleFile() bool {
+	return !p.hasDirectories() && !p.hasFiles() && p.Archive == "" && p.RemoteURL == ""
+}
+
+// loadFiles loads the Files into a new cache, in order, so that the later files are merged over the former ones.
+func (p *Provider) loadFiles() (*fileCache, error) {
+	if _, err := p.mergeStrategy(); err != nil {
+		return nil, err
+	}
+
+	cache := newFileCache()
+	for _, file := range p.resolved().files {
+		entry, err := p.loadCachedFile(file)
+		if err != nil {
+			return nil, err
+		}
+		cache.add(filepath.Clean(file), entry)
+	}
+	return cache, nil
+}
+
+// isListedFile returns true if the path is one of the Files, or a file they include.
+func (p *Provider) isListedFile(name string) bool {
+	for _, file := range p.resolved().files {
+		if filepath.Clean(file) == filepath.Clean(name) {
+			return true
+		}
+	}
+
+	cache, ok := p.cache.Get().(*fileCache)
+	return ok && len(cache.dependents(name)) > 0
+}
+
+// filesDirectories returns the directories of the Files, without duplicates.
+func (p *Provider) filesDirectories() []string {
+	var directories []string
+	seen := make(map[string]struct{})
+	for _, file := range p.resolved().files {
+		directory := filepath.Dir(file)
+		if _, exists := seen[directory]; !exists {
+			seen[directory] = struct{}{}
+			directories = append(directories, directory)
+		}
+	}
+	return directories
+}
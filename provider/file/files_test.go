@@ -0,0 +1,92 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/fsnotify.v1"
+)
+
+func TestBuildConfigurationFiles(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		mergeStrategy string
+		expectedURL   string
+	}{
+		{
+			desc:        "skip",
+			expectedURL: "http://172.17.0.1:80",
+		},
+		{
+			desc:          "replace",
+			mergeStrategy: mergeStrategyReplace,
+			expectedURL:   "http://172.17.0.2:80",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testfiles")
+			defer os.RemoveAll(tempDir)
+			overridesDir := createSubDir(t, tempDir, "overrides")
+
+			// Loaded in the order of the list, whatever their names
+			createFile(t, tempDir, "z-base.toml", backendWithURL("backend1", "http://172.17.0.1:80"), backendWithURL("backend2", "http://172.17.0.1:80"))
+			createFile(t, overridesDir, "a-overrides.toml", backendWithURL("backend1", "http://172.17.0.2:80"))
+			createFile(t, tempDir, "unlisted.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
+
+			pvd := &Provider{
+				Files:         StringList{filepath.Join(tempDir, "z-base.toml"), filepath.Join(overridesDir, "a-overrides.toml")},
+				MergeStrategy: test.mergeStrategy,
+			}
+
+			configuration, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			require.Len(t, configuration.Backends, 2)
+			assert.Equal(t, test.expectedURL, configuration.Backends["backend1"].Servers["server1"].URL)
+
+			directories, err := pvd.watchedDirectories()
+			require.NoError(t, err)
+			assert.Equal(t, []string{tempDir, overridesDir}, directories)
+
+			assert.True(t, pvd.isWatchedEvent(fsnotify.Event{Name: filepath.Join(overridesDir, "a-overrides.toml")}))
+			assert.False(t, pvd.isWatchedEvent(fsnotify.Event{Name: filepath.Join(tempDir, "unlisted.toml")}))
+		})
+	}
+}
+
+func TestProvideFilesAndWatch(t *testing.T) {
+	tempDir := createTempDir(t, "testfiles")
+	defer os.RemoveAll(tempDir)
+
+	frontendsFile := createFile(t, tempDir, "frontends.toml", createFrontendConfiguration(2))
+	backendsFile := createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, withDebounce(100*time.Millisecond), func(pvd *Provider) {
+		pvd.Files = StringList{frontendsFile.Name(), backendsFile.Name()}
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "initial config")
+	require.NoError(t, err)
+
+	expectedNumBackends = 3
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(3))
+
+	err = waitForSignal(signal, 2*time.Second, "changed file")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,143 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containous/traefik/types"
+	"github.com/ghodss/yaml"
+)
+
+// format is the syntax a configuration file is written in.
+type format string
+
+const (
+	formatTOML format = "TOML"
+	formatYAML format = "YAML"
+	formatJSON format = "JSON"
+)
+
+// gzipExtension is the extension of the gzip-compressed files, which are decompressed before being decoded.
+const gzipExtension = ".gz"
+
+func isGzipFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), gzipExtension)
+}
+
+// uncompressedName returns filename without its compression extension.
+func uncompressedName(filename string) string {
+	if isGzipFile(filename) {
+		return filename[:len(filename)-len(gzipExtension)]
+	}
+	return filename
+}
+
+// configExtensions lists the extensions of the configuration files searched in a directory, compressed or not.
+var configExtensions = []string{".toml", ".tml", ".yml", ".yaml", ".json", templateExtension}
+
+// formatFromFilename returns the format matching the file extension, ignoring the compression and template extensions,
+// and false if the extension is not a supported one.
+func formatFromFilename(filename string) (format, bool) {
+	name := strings.TrimSuffix(uncompressedName(filename), templateExtension)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".toml", ".tml":
+		return formatTOML, true
+	case ".yml", ".yaml":
+		return formatYAML, true
+	case ".json":
+		return formatJSON, true
+	}
+	return "", false
+}
+
+// fileContent is the content of a configuration file.
+type fileContent struct {
+	types.Configuration
+	Include include `json:"include,omitempty"`
+	// Disabled skips the definitions of the file, to disable it without removing it
+	Disabled bool `json:"disabled,omitempty"`
+	// Profiles holds the definitions overlaid on the base ones when their profile is the active one
+	Profiles map[string]*types.Configuration `json:"profiles,omitempty"`
+	// templateFiles holds the files read to render the content of a template, such as the templates it includes
+	templateFiles []string
+}
+
+// include lists the files to load along with a configuration file.
+// Relative paths are relative to the directory of the including file.
+type include struct {
+	Files []string `json:"files,omitempty"`
+}
+
+// decodeFileContent decodes content written in the given format.
+func decodeFileContent(content []byte, f format) (*fileContent, error) {
+	fc := &fileContent{
+		Configuration: types.Configuration{
+			Frontends: make(map[string]*types.Frontend),
+			Backends:  make(map[string]*types.Backend),
+		},
+	}
+
+	if err := decode(content, f, fc); err != nil {
+		return nil, fmt.Errorf("unable to decode %s configuration: %v", f, err)
+	}
+	return fc, nil
+}
+
+// knownKeys are the top-level keys of a configuration file, in lower case.
+var knownKeys = map[string]struct{}{
+	"backends":         {},
+	"frontends":        {},
+	"tlsconfiguration": {},
+	"include":          {},
+	"profiles":         {},
+	"disabled":         {},
+}
+
+// unknownKeys returns the top-level keys of the content which are not part of a configuration file, sorted.
+// Keys are matched case-insensitively, as the decoders do.
+func unknownKeys(content []byte, f format) ([]string, error) {
+	values := make(map[string]interface{})
+	if err := decode(content, f, &values); err != nil {
+		return nil, fmt.Errorf("unable to decode %s configuration: %v", f, err)
+	}
+
+	var keys []string
+	for key := range values {
+		if _, exists := knownKeys[strings.ToLower(key)]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// decodeValues decodes the values written in the given format.
+func decodeValues(content []byte, f format) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if err := decode(content, f, &values); err != nil {
+		return nil, fmt.Errorf("unable to decode %s values: %v", f, err)
+	}
+	return values, nil
+}
+
+func decode(content []byte, f format, target interface{}) error {
+	switch f {
+	case formatYAML:
+		// Convert to JSON first so the json tags of types.Configuration are honored
+		var err error
+		content, err = yaml.YAMLToJSON(content)
+		if err != nil {
+			return err
+		}
+		fallthrough
+	case formatJSON:
+		return json.Unmarshal(content, target)
+	default:
+		_, err := toml.Decode(string(content), target)
+		return err
+	}
+}
@@ -0,0 +1,210 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatFromFilename(t *testing.T) {
+	testCases := []struct {
+		filename       string
+		expectedFormat format
+		expectedOk     bool
+	}{
+		{filename: "rules.toml", expectedFormat: formatTOML, expectedOk: true},
+		{filename: "rules.tml", expectedFormat: formatTOML, expectedOk: true},
+		{filename: "rules.yml", expectedFormat: formatYAML, expectedOk: true},
+		{filename: "rules.YAML", expectedFormat: formatYAML, expectedOk: true},
+		{filename: "rules.json", expectedFormat: formatJSON, expectedOk: true},
+		{filename: "rules.toml.gz", expectedFormat: formatTOML, expectedOk: true},
+		{filename: "rules.yml.GZ", expectedFormat: formatYAML, expectedOk: true},
+		{filename: "rules.toml.tmpl", expectedFormat: formatTOML, expectedOk: true},
+		{filename: "rules.yml.tmpl", expectedFormat: formatYAML, expectedOk: true},
+		{filename: "rules.yaml.tmpl", expectedFormat: formatYAML, expectedOk: true},
+		{filename: "rules.json.tmpl", expectedFormat: formatJSON, expectedOk: true},
+		{filename: "rules.yml.tmpl.gz", expectedFormat: formatYAML, expectedOk: true},
+		{filename: "rules.tmpl", expectedOk: false},
+		{filename: "rules.gz", expectedOk: false},
+		{filename: "rules.txt", expectedOk: false},
+		{filename: "rules", expectedOk: false},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.filename, func(t *testing.T) {
+			t.Parallel()
+
+			f, ok := formatFromFilename(test.filename)
+			assert.Equal(t, test.expectedOk, ok)
+			assert.Equal(t, test.expectedFormat, f)
+		})
+	}
+}
+
+func TestDecodeFileContent(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		format  format
+		content string
+	}{
+		{
+			desc:   "TOML",
+			format: formatTOML,
+			content: `
+[backends.backend1.servers.server1]
+url = "http://172.17.0.1:80"
+[frontends.frontend1]
+backend = "backend1"
+[include]
+files = ["common/backends.toml"]
+`,
+		},
+		{
+			desc:   "YAML",
+			format: formatYAML,
+			content: `
+backends:
+  backend1:
+    servers:
+      server1:
+        url: http://172.17.0.1:80
+frontends:
+  frontend1:
+    backend: backend1
+include:
+  files:
+  - common/backends.toml
+`,
+		},
+		{
+			desc:    "JSON",
+			format:  formatJSON,
+			content: `{"backends": {"backend1": {"servers": {"server1": {"url": "http://172.17.0.1:80"}}}}, "frontends": {"frontend1": {"backend": "backend1"}}, "include": {"files": ["common/backends.toml"]}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			configuration, err := decodeFileContent([]byte(test.content), test.format)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"common/backends.toml"}, configuration.Include.Files)
+
+			require.Contains(t, configuration.Backends, "backend1")
+			assert.Equal(t, "http://172.17.0.1:80", configuration.Backends["backend1"].Servers["server1"].URL)
+			require.Contains(t, configuration.Frontends, "frontend1")
+			assert.Equal(t, "backend1", configuration.Frontends["frontend1"].Backend)
+		})
+	}
+}
+
+func TestDecodeFileContentErrorNamesFormat(t *testing.T) {
+	for _, f := range []format{formatTOML, formatYAML, formatJSON} {
+		_, err := decodeFileContent([]byte("{{ invalid"), f)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), string(f))
+	}
+}
+
+func TestDecodeValues(t *testing.T) {
+	testCases := []struct {
+		format  format
+		content string
+	}{
+		{format: formatTOML, content: "environment = \"staging\"\nreplicas = 2\n"},
+		{format: formatYAML, content: "environment: staging\nreplicas: 2\n"},
+		{format: formatJSON, content: `{"environment": "staging", "replicas": 2}`},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(string(test.format), func(t *testing.T) {
+			t.Parallel()
+
+			values, err := decodeValues([]byte(test.content), test.format)
+			require.NoError(t, err)
+
+			assert.Equal(t, "staging", values["environment"])
+			assert.EqualValues(t, 2, values["replicas"])
+		})
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	testCases := []struct {
+		format   format
+		content  string
+		expected []string
+	}{
+		{
+			format:   formatTOML,
+			content:  "[metadata]\nowner = \"team\"\n[Backends.backend1.servers.server1]\nurl = \"http://172.17.0.1:80\"\n[[TLSConfiguration]]\nentryPoints = [\"https\"]\n",
+			expected: []string{"metadata"},
+		},
+		{
+			format:   formatYAML,
+			content:  "metadata:\n  owner: team\nannotations: {}\nbackends: {}\ninclude:\n  files: []\n",
+			expected: []string{"annotations", "metadata"},
+		},
+		{
+			format:  formatJSON,
+			content: `{"backends": {}, "frontends": {}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(string(test.format), func(t *testing.T) {
+			t.Parallel()
+
+			keys, err := unknownKeys([]byte(test.content), test.format)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, keys)
+		})
+	}
+}
+
+func TestLoadFileConfigLenientDecode(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		lenientDecode bool
+		expectedError string
+	}{
+		{
+			desc:          "lenient",
+			lenientDecode: true,
+		},
+		{
+			desc:          "strict",
+			expectedError: "unknown top-level keys: metadata",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdecode")
+			defer os.RemoveAll(tempDir)
+
+			tempFile := createFile(t, tempDir, "rules.toml", "[metadata]\nowner = \"team\"\n", createBackendConfiguration(1))
+
+			pvd := &Provider{Filename: tempFile.Name(), LenientDecode: test.lenientDecode}
+			configuration, err := pvd.loadFileConfig(tempFile.Name())
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, configuration.Backends, 1)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package file
+
+import (
+	"sync/atomic"
+)
+
+// Ready returns true once the provider has loaded and sent a configuration,
+// so that a readiness probe fails as long as no valid configuration could be loaded.
+// It stays true when a later reload fails, since the previous configuration stays in use.
+func (p *Provider) Ready() bool {
+	return p.lastConfiguration.Get() != nil
+}
+
+// LastLoadSucceeded returns true if the last loading of the configuration succeeded,
+// whether it was sent or it was identical to the one in use.
+// It returns false before the first configuration is loaded, and after a reload failed until a reload succeeds.
+func (p *Provider) LastLoadSucceeded() bool {
+	return p.Ready() && atomic.LoadInt32(&p.staleReloads) == 0
+}
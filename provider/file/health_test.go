@@ -0,0 +1,58 @@
+package file
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvideReadiness(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{Directory: tempDir, ProviderName: "file"}
+
+	assert.False(t, pvd.Ready())
+	assert.False(t, pvd.LastLoadSucceeded())
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	require.NoError(t, pvd.Provide(configurationChan, pool, nil))
+	assert.True(t, pvd.Ready())
+	assert.True(t, pvd.LastLoadSucceeded())
+
+	createFile(t, tempDir, "backends.toml", "[backends\n")
+	pvd.Reload(configurationChan)
+	assert.True(t, pvd.Ready())
+	assert.False(t, pvd.LastLoadSucceeded())
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(3))
+	pvd.Reload(configurationChan)
+	assert.True(t, pvd.Ready())
+	assert.True(t, pvd.LastLoadSucceeded())
+}
+
+func TestProvideReadinessInvalidConfiguration(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", "[backends\n")
+
+	pvd := &Provider{Directory: tempDir, ProviderName: "file"}
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	require.Error(t, pvd.Provide(make(chan types.ConfigMessage, 10), pool, nil))
+	assert.False(t, pvd.Ready())
+	assert.False(t, pvd.LastLoadSucceeded())
+}
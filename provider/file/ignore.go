@@ -0,0 +1,93 @@
+package file
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFilename is the name of the file of the configured directory listing the paths not to load nor watch.
+const ignoreFilename = ".traefikignore"
+
+// ignorePatterns are the glob patterns of the paths not to load nor watch.
+// Patterns without a slash match the name of the files and directories at any depth,
+// the other ones match their path relative to the configured directory.
+type ignorePatterns []string
+
+// readIgnoreFile reads the patterns of the ignore file of the directory, one per line.
+// Blank lines and lines starting with # are skipped.
+func readIgnoreFile(directory string) (ignorePatterns, error) {
+	ignoreFile := filepath.Join(directory, ignoreFilename)
+
+	content, err := ioutil.ReadFile(ignoreFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns ignorePatterns
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern := strings.Trim(line, "/")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in %s: %v", line, ignoreFile, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// matches returns true if the path relative to the configured directory matches one of the patterns.
+func (patterns ignorePatterns) matches(relativePath string) bool {
+	relativePath = filepath.ToSlash(relativePath)
+
+	for _, pattern := range patterns {
+		target := relativePath
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(relativePath)
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isIgnored returns true if the path, in a configured directory, matches the patterns of the ignore file of this directory.
+func (p *Provider) isIgnored(name string) bool {
+	root := p.rootDirectory(name)
+	if root == "" {
+		return false
+	}
+
+	allPatterns, _ := p.ignorePatterns.Get().(map[string]ignorePatterns)
+	patterns := allPatterns[filepath.Clean(root)]
+	if len(patterns) == 0 {
+		return false
+	}
+
+	relativePath, err := filepath.Rel(root, name)
+	if err != nil || relativePath == "." || strings.HasPrefix(relativePath, "..") {
+		return false
+	}
+	return patterns.matches(relativePath)
+}
+
+// isIgnoreFile returns true if the path is the ignore file of a configured directory.
+func (p *Provider) isIgnoreFile(name string) bool {
+	for _, directory := range p.directories() {
+		if filepath.Clean(name) == filepath.Join(directory, ignoreFilename) {
+			return true
+		}
+	}
+	return false
+}
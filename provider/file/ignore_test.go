@@ -0,0 +1,89 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadIgnoreFile(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	patterns, err := readIgnoreFile(tempDir)
+	require.NoError(t, err)
+	assert.Empty(t, patterns)
+
+	createFile(t, tempDir, ignoreFilename, `
+# Scratch files
+*.draft.toml
+
+/scratch/
+  sub/old.toml
+`)
+
+	patterns, err = readIgnoreFile(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, ignorePatterns{"*.draft.toml", "scratch", "sub/old.toml"}, patterns)
+
+	createFile(t, tempDir, ignoreFilename, "[")
+
+	_, err = readIgnoreFile(tempDir)
+	assert.Error(t, err)
+}
+
+func TestIgnorePatternsMatches(t *testing.T) {
+	patterns := ignorePatterns{"*.draft.toml", "scratch", "sub/old.toml"}
+
+	testCases := []struct {
+		relativePath string
+		expected     bool
+	}{
+		{relativePath: "rules.toml", expected: false},
+		{relativePath: "rules.draft.toml", expected: true},
+		{relativePath: filepath.Join("sub", "rules.draft.toml"), expected: true},
+		{relativePath: "scratch", expected: true},
+		{relativePath: filepath.Join("sub", "scratch"), expected: true},
+		{relativePath: filepath.Join("sub", "old.toml"), expected: true},
+		{relativePath: "old.toml", expected: false},
+		{relativePath: filepath.Join("other", "sub", "old.toml"), expected: false},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.relativePath, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, patterns.matches(test.relativePath))
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryIgnoreFile(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	scratchDir := createSubDir(t, tempDir, "scratch")
+	createSubDir(t, tempDir, "sub")
+	createFile(t, tempDir, ignoreFilename, "*.draft.toml\nscratch\n")
+	createFile(t, tempDir, "rules.toml", backendWithURL("backend1", "http://172.17.0.1:80"))
+	createFile(t, tempDir, "rules.draft.toml", backendWithURL("backend2", "http://172.17.0.1:80"))
+	createFile(t, scratchDir, "rules.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
+
+	pvd := &Provider{Directory: tempDir}
+	configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+	require.NoError(t, err)
+	assert.Len(t, configuration.Backends, 1)
+	assert.Contains(t, configuration.Backends, "backend1")
+
+	directories, err := pvd.getDirectoriesRecursively(tempDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{tempDir, filepath.Join(tempDir, "sub")}, directories)
+
+	assert.True(t, pvd.isWatchedPath(filepath.Join(tempDir, ignoreFilename)))
+	assert.False(t, pvd.isWatchedPath(filepath.Join(tempDir, "rules.draft.toml")))
+	assert.False(t, pvd.isWatchedPath(scratchDir))
+}
@@ -0,0 +1,30 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StringList holds the values of the list options, such as the Directories, the Files, the Extensions or the EntryPointFilter
+type StringList []string
+
+// Set adds the values of str, separated by , or ;, to the parser
+func (l *StringList) Set(str string) error {
+	fargs := func(c rune) bool {
+		return c == ',' || c == ';'
+	}
+	slice := strings.FieldsFunc(str, fargs)
+	*l = append(*l, slice...)
+	return nil
+}
+
+// Get []string
+func (l *StringList) Get() interface{} { return *l }
+
+// String returns the values in a string
+func (l *StringList) String() string { return fmt.Sprintf("%v", *l) }
+
+// SetValue sets []string into the parser
+func (l *StringList) SetValue(val interface{}) {
+	*l = val.(StringList)
+}
@@ -0,0 +1,17 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringListSet(t *testing.T) {
+	var list StringList
+	require.NoError(t, list.Set("/etc/traefik/base,/etc/traefik/staging;/etc/traefik/local"))
+	require.NoError(t, list.Set("/etc/traefik/overrides"))
+
+	assert.Equal(t, StringList{"/etc/traefik/base", "/etc/traefik/staging", "/etc/traefik/local", "/etc/traefik/overrides"}, list.Get())
+	assert.Equal(t, "[/etc/traefik/base /etc/traefik/staging /etc/traefik/local /etc/traefik/overrides]", list.String())
+}
@@ -0,0 +1,209 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/tls"
+	"github.com/containous/traefik/types"
+)
+
+// Strategies to combine frontends and backends defined with the same name in several files.
+const (
+	// mergeStrategySkip keeps the first definition and ignores the following ones.
+	mergeStrategySkip = "skip"
+	// mergeStrategyReplace keeps the last definition.
+	mergeStrategyReplace = "replace"
+	// mergeStrategyMerge combines the definitions, the first one taking precedence on conflicts.
+	mergeStrategyMerge = "merge"
+	// mergeStrategyMergeServers combines the servers of the backends, and fails on conflicting settings.
+	// Frontends are skipped as with mergeStrategySkip.
+	mergeStrategyMergeServers = "mergeServers"
+	// mergeStrategyOverride keeps the last definition without warning, for the files of the OverrideDirectory.
+	// It can not be configured.
+	mergeStrategyOverride = "override"
+)
+
+func (p *Provider) mergeStrategy() (string, error) {
+	switch p.MergeStrategy {
+	case "":
+		return mergeStrategySkip, nil
+	case mergeStrategySkip, mergeStrategyReplace, mergeStrategyMerge, mergeStrategyMergeServers:
+		return p.MergeStrategy, nil
+	default:
+		return "", fmt.Errorf("unknown merge strategy %q, expected one of %q, %q, %q or %q",
+			p.MergeStrategy, mergeStrategySkip, mergeStrategyReplace, mergeStrategyMerge, mergeStrategyMergeServers)
+	}
+}
+
+// mergeBackend adds the backend defined in origin to the backends, following the strategy if it is already defined.
+// It fails if the definitions can not be combined with mergeStrategyMergeServers.
+func mergeBackend(strategy string, backends map[string]*types.Backend, origins map[string]string, name, origin string, backend *types.Backend, warns *warnings) error {
+	existing, exists := backends[name]
+	if !exists {
+		backends[name] = backend
+		origins[name] = origin
+		return nil
+	}
+
+	switch strategy {
+	case mergeStrategyOverride:
+		log.Debugf("Backend %s of %s overrides the one of %s", name, origin, origins[name])
+		backends[name] = backend
+		origins[name] = origin
+	case mergeStrategyReplace:
+		warns.add("Backend %s of %s already configured in %s, replacing it (merge strategy %s)", name, origin, origins[name], strategy)
+		backends[name] = backend
+		origins[name] = origin
+	case mergeStrategyMerge:
+		warns.add("Backend %s of %s already configured in %s, merging it (merge strategy %s)", name, origin, origins[name], strategy)
+		backends[name] = mergeBackends(existing, backend)
+	case mergeStrategyMergeServers:
+		merged, err := mergeBackendServers(existing, backend)
+		if err != nil {
+			return fmt.Errorf("backend %s of %s conflicts with the one of %s: %v (merge strategy %s)", name, origin, origins[name], err, strategy)
+		}
+		log.Debugf("Servers of backend %s of %s merged with the ones of %s", name, origin, origins[name])
+		backends[name] = merged
+	default:
+		warns.add("Backend %s of %s already configured in %s, skipping (merge strategy %s)", name, origin, origins[name], strategy)
+	}
+	return nil
+}
+
+// mergeBackendServers unions the servers of both backends, whose servers with the same name must be identical,
+// as well as their settings defined in both.
+func mergeBackendServers(first, second *types.Backend) (*types.Backend, error) {
+	for serverName, server := range second.Servers {
+		if existing, exists := first.Servers[serverName]; exists && existing != server {
+			return nil, fmt.Errorf("server %s differs", serverName)
+		}
+	}
+
+	if first.LoadBalancer != nil && second.LoadBalancer != nil && !reflect.DeepEqual(first.LoadBalancer, second.LoadBalancer) {
+		return nil, errors.New("load balancer settings differ")
+	}
+	if first.HealthCheck != nil && second.HealthCheck != nil && !reflect.DeepEqual(first.HealthCheck, second.HealthCheck) {
+		return nil, errors.New("health check settings differ")
+	}
+	if first.CircuitBreaker != nil && second.CircuitBreaker != nil && !reflect.DeepEqual(first.CircuitBreaker, second.CircuitBreaker) {
+		return nil, errors.New("circuit breaker settings differ")
+	}
+	if first.MaxConn != nil && second.MaxConn != nil && !reflect.DeepEqual(first.MaxConn, second.MaxConn) {
+		return nil, errors.New("max connections settings differ")
+	}
+
+	return mergeBackends(first, second), nil
+}
+
+// mergeBackends unions the servers of both backends.
+// On conflicting server names or settings, the values of the first backend are kept.
+func mergeBackends(first, second *types.Backend) *types.Backend {
+	merged := *first
+
+	merged.Servers = make(map[string]types.Server, len(first.Servers)+len(second.Servers))
+	for serverName, server := range second.Servers {
+		merged.Servers[serverName] = server
+	}
+	for serverName, server := range first.Servers {
+		merged.Servers[serverName] = server
+	}
+
+	if merged.LoadBalancer == nil {
+		merged.LoadBalancer = second.LoadBalancer
+	}
+	if merged.HealthCheck == nil {
+		merged.HealthCheck = second.HealthCheck
+	}
+	if merged.CircuitBreaker == nil {
+		merged.CircuitBreaker = second.CircuitBreaker
+	}
+	if merged.MaxConn == nil {
+		merged.MaxConn = second.MaxConn
+	}
+
+	return &merged
+}
+
+func mergeFrontend(strategy string, frontends map[string]*types.Frontend, origins map[string]string, name, origin string, frontend *types.Frontend, warns *warnings) {
+	existing, exists := frontends[name]
+	if !exists {
+		frontends[name] = frontend
+		origins[name] = origin
+		return
+	}
+
+	switch strategy {
+	case mergeStrategyOverride:
+		log.Debugf("Frontend %s of %s overrides the one of %s", name, origin, origins[name])
+		frontends[name] = frontend
+		origins[name] = origin
+	case mergeStrategyReplace:
+		warns.add("Frontend %s of %s already configured in %s, replacing it (merge strategy %s)", name, origin, origins[name], strategy)
+		frontends[name] = frontend
+		origins[name] = origin
+	case mergeStrategyMerge:
+		warns.add("Frontend %s of %s already configured in %s, merging it (merge strategy %s)", name, origin, origins[name], strategy)
+		frontends[name] = mergeFrontends(existing, frontend)
+	default:
+		warns.add("Frontend %s of %s already configured in %s, skipping (merge strategy %s)", name, origin, origins[name], strategy)
+	}
+}
+
+// mergeFrontends unions the routes and error pages of both frontends.
+// All other settings are the ones of the first frontend.
+func mergeFrontends(first, second *types.Frontend) *types.Frontend {
+	merged := *first
+
+	merged.Routes = make(map[string]types.Route, len(first.Routes)+len(second.Routes))
+	for routeName, route := range second.Routes {
+		merged.Routes[routeName] = route
+	}
+	for routeName, route := range first.Routes {
+		merged.Routes[routeName] = route
+	}
+
+	if len(second.Errors) > 0 {
+		merged.Errors = make(map[string]*types.ErrorPage, len(first.Errors)+len(second.Errors))
+		for errorName, errorPage := range second.Errors {
+			merged.Errors[errorName] = errorPage
+		}
+		for errorName, errorPage := range first.Errors {
+			merged.Errors[errorName] = errorPage
+		}
+	}
+
+	return &merged
+}
+
+// tlsConfigurationKey identifies a TLS configuration by its certificate, key and entry points,
+// so that the same TLS configuration defined in several files is loaded once.
+func tlsConfigurationKey(conf *tls.Configuration) string {
+	entryPoints := append([]string(nil), conf.EntryPoints...)
+	sort.Strings(entryPoints)
+
+	var certFile, keyFile tls.FileOrContent
+	if conf.Certificate != nil {
+		certFile = conf.Certificate.CertFile
+		keyFile = conf.Certificate.KeyFile
+	}
+	return fmt.Sprintf("%q %q %q", certFile, keyFile, entryPoints)
+}
+
+// mergeTLSConfigurations appends the TLS configurations of the source not already configured,
+// sources holding the file defining each configured TLS configuration by its key.
+func mergeTLSConfigurations(configurations []*tls.Configuration, sources map[string]string, source string, added []*tls.Configuration, warns *warnings) []*tls.Configuration {
+	for _, conf := range added {
+		key := tlsConfigurationKey(conf)
+		if existingSource, exists := sources[key]; exists {
+			warns.add("TLS configuration of entry points %v of %s already configured in %s, skipping", conf.EntryPoints, source, existingSource)
+			continue
+		}
+		sources[key] = source
+		configurations = append(configurations, conf)
+	}
+	return configurations
+}
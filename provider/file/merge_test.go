@@ -0,0 +1,329 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/tls"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeBackend(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		strategy       string
+		expected       *types.Backend
+		expectedOrigin string
+	}{
+		{
+			desc:           "skip",
+			strategy:       mergeStrategySkip,
+			expectedOrigin: "a.toml",
+			expected: &types.Backend{
+				Servers: map[string]types.Server{
+					"server1": {URL: "http://10.0.0.1:80"},
+				},
+			},
+		},
+		{
+			desc:           "replace",
+			strategy:       mergeStrategyReplace,
+			expectedOrigin: "b.toml",
+			expected: &types.Backend{
+				Servers: map[string]types.Server{
+					"server1": {URL: "http://10.0.0.3:80"},
+					"server2": {URL: "http://10.0.0.2:80"},
+				},
+				LoadBalancer: &types.LoadBalancer{Method: "drr"},
+			},
+		},
+		{
+			desc:           "merge",
+			strategy:       mergeStrategyMerge,
+			expectedOrigin: "a.toml",
+			expected: &types.Backend{
+				Servers: map[string]types.Server{
+					"server1": {URL: "http://10.0.0.1:80"},
+					"server2": {URL: "http://10.0.0.2:80"},
+				},
+				LoadBalancer: &types.LoadBalancer{Method: "drr"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backends := map[string]*types.Backend{
+				"backend1": {
+					Servers: map[string]types.Server{
+						"server1": {URL: "http://10.0.0.1:80"},
+					},
+				},
+			}
+
+			origins := map[string]string{"backend1": "a.toml"}
+			err := mergeBackend(test.strategy, backends, origins, "backend1", "b.toml", &types.Backend{
+				Servers: map[string]types.Server{
+					"server1": {URL: "http://10.0.0.3:80"},
+					"server2": {URL: "http://10.0.0.2:80"},
+				},
+				LoadBalancer: &types.LoadBalancer{Method: "drr"},
+			}, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, backends["backend1"])
+			assert.Equal(t, test.expectedOrigin, origins["backend1"])
+		})
+	}
+}
+
+func TestMergeBackendServers(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		second        *types.Backend
+		expected      *types.Backend
+		expectedError string
+	}{
+		{
+			desc: "servers of both backends",
+			second: &types.Backend{
+				Servers: map[string]types.Server{
+					"server1": {URL: "http://10.0.0.1:80", Weight: 1},
+					"server2": {URL: "http://10.0.0.2:80", Weight: 1},
+				},
+				LoadBalancer: &types.LoadBalancer{Method: "wrr"},
+			},
+			expected: &types.Backend{
+				Servers: map[string]types.Server{
+					"server1": {URL: "http://10.0.0.1:80", Weight: 1},
+					"server2": {URL: "http://10.0.0.2:80", Weight: 1},
+				},
+				LoadBalancer: &types.LoadBalancer{Method: "wrr"},
+				HealthCheck:  &types.HealthCheck{Path: "/health"},
+			},
+		},
+		{
+			desc: "settings defined in a single backend",
+			second: &types.Backend{
+				Servers: map[string]types.Server{"server2": {URL: "http://10.0.0.2:80"}},
+				MaxConn: &types.MaxConn{Amount: 10, ExtractorFunc: "request.host"},
+			},
+			expected: &types.Backend{
+				Servers: map[string]types.Server{
+					"server1": {URL: "http://10.0.0.1:80", Weight: 1},
+					"server2": {URL: "http://10.0.0.2:80"},
+				},
+				LoadBalancer: &types.LoadBalancer{Method: "wrr"},
+				HealthCheck:  &types.HealthCheck{Path: "/health"},
+				MaxConn:      &types.MaxConn{Amount: 10, ExtractorFunc: "request.host"},
+			},
+		},
+		{
+			desc: "server with the same name",
+			second: &types.Backend{
+				Servers: map[string]types.Server{"server1": {URL: "http://10.0.0.2:80", Weight: 1}},
+			},
+			expectedError: "server server1 differs",
+		},
+		{
+			desc: "different load balancer",
+			second: &types.Backend{
+				Servers:      map[string]types.Server{"server2": {URL: "http://10.0.0.2:80"}},
+				LoadBalancer: &types.LoadBalancer{Method: "drr"},
+			},
+			expectedError: "load balancer settings differ",
+		},
+		{
+			desc: "different health check",
+			second: &types.Backend{
+				Servers:     map[string]types.Server{"server2": {URL: "http://10.0.0.2:80"}},
+				HealthCheck: &types.HealthCheck{Path: "/ping"},
+			},
+			expectedError: "health check settings differ",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backends := map[string]*types.Backend{
+				"backend1": {
+					Servers:      map[string]types.Server{"server1": {URL: "http://10.0.0.1:80", Weight: 1}},
+					LoadBalancer: &types.LoadBalancer{Method: "wrr"},
+					HealthCheck:  &types.HealthCheck{Path: "/health"},
+				},
+			}
+			origins := map[string]string{"backend1": "a.toml"}
+
+			err := mergeBackend(mergeStrategyMergeServers, backends, origins, "backend1", "b.toml", test.second, nil)
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "backend backend1 of b.toml conflicts with the one of a.toml: "+test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, backends["backend1"])
+			assert.Equal(t, "a.toml", origins["backend1"])
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryMergeServers(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "group-a.toml", `
+[backends.backend1.loadBalancer]
+method = "drr"
+[backends.backend1.servers.a1]
+url = "http://10.0.1.1:80"
+[backends.backend1.servers.a2]
+url = "http://10.0.1.2:80"
+`)
+	createFile(t, tempDir, "group-b.toml", `
+[backends.backend1.loadBalancer]
+method = "drr"
+[backends.backend1.servers.b1]
+url = "http://10.0.2.1:80"
+`)
+
+	pvd := &Provider{MergeStrategy: mergeStrategyMergeServers, WarningsAsErrors: true}
+	configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+	require.NoError(t, err)
+
+	require.Contains(t, configuration.Backends, "backend1")
+	assert.Equal(t, map[string]types.Server{
+		"a1": {URL: "http://10.0.1.1:80"},
+		"a2": {URL: "http://10.0.1.2:80"},
+		"b1": {URL: "http://10.0.2.1:80"},
+	}, configuration.Backends["backend1"].Servers)
+
+	createFile(t, tempDir, "group-c.toml", `
+[backends.backend1.loadBalancer]
+method = "wrr"
+[backends.backend1.servers.c1]
+url = "http://10.0.3.1:80"
+`)
+	_, err = pvd.loadFileConfigFromDirectory(tempDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load balancer settings differ")
+}
+
+func TestMergeFrontend(t *testing.T) {
+	frontends := map[string]*types.Frontend{
+		"frontend1": {
+			Backend: "backend1",
+			Routes:  map[string]types.Route{"route1": {Rule: "Host:foo.localhost"}},
+		},
+	}
+
+	mergeFrontend(mergeStrategyMerge, frontends, map[string]string{"frontend1": "a.toml"}, "frontend1", "b.toml", &types.Frontend{
+		Backend: "backend2",
+		Routes:  map[string]types.Route{"route2": {Rule: "Path:/bar"}},
+	}, nil)
+
+	require.Contains(t, frontends, "frontend1")
+	assert.Equal(t, "backend1", frontends["frontend1"].Backend)
+	assert.Len(t, frontends["frontend1"].Routes, 2)
+}
+
+func TestMergeStrategy(t *testing.T) {
+	strategy, err := (&Provider{}).mergeStrategy()
+	require.NoError(t, err)
+	assert.Equal(t, mergeStrategySkip, strategy)
+
+	_, err = (&Provider{MergeStrategy: "unknown"}).mergeStrategy()
+	assert.Error(t, err)
+}
+
+func TestTLSConfigurationKey(t *testing.T) {
+	conf := &tls.Configuration{
+		EntryPoints: []string{"https", "http"},
+		Certificate: &tls.Certificate{CertFile: "cert.pem", KeyFile: "key.pem"},
+	}
+
+	testCases := []struct {
+		desc     string
+		other    *tls.Configuration
+		expected bool
+	}{
+		{
+			desc: "same content",
+			other: &tls.Configuration{
+				EntryPoints: []string{"https", "http"},
+				Certificate: &tls.Certificate{CertFile: "cert.pem", KeyFile: "key.pem"},
+			},
+			expected: true,
+		},
+		{
+			desc: "entry points in another order",
+			other: &tls.Configuration{
+				EntryPoints: []string{"http", "https"},
+				Certificate: &tls.Certificate{CertFile: "cert.pem", KeyFile: "key.pem"},
+			},
+			expected: true,
+		},
+		{
+			desc: "other entry points",
+			other: &tls.Configuration{
+				EntryPoints: []string{"https"},
+				Certificate: &tls.Certificate{CertFile: "cert.pem", KeyFile: "key.pem"},
+			},
+		},
+		{
+			desc: "other key",
+			other: &tls.Configuration{
+				EntryPoints: []string{"https", "http"},
+				Certificate: &tls.Certificate{CertFile: "cert.pem", KeyFile: "other.pem"},
+			},
+		},
+		{
+			desc:  "no certificate",
+			other: &tls.Configuration{EntryPoints: []string{"https", "http"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, tlsConfigurationKey(conf) == tlsConfigurationKey(test.other))
+		})
+	}
+}
+
+func TestMergeTLSConfigurations(t *testing.T) {
+	first := &tls.Configuration{
+		EntryPoints: []string{"https"},
+		Certificate: &tls.Certificate{CertFile: "cert.pem", KeyFile: "key.pem"},
+	}
+	duplicate := &tls.Configuration{
+		EntryPoints: []string{"https"},
+		Certificate: &tls.Certificate{CertFile: "cert.pem", KeyFile: "key.pem"},
+	}
+	other := &tls.Configuration{
+		EntryPoints: []string{"https"},
+		Certificate: &tls.Certificate{CertFile: "other.pem", KeyFile: "other-key.pem"},
+	}
+
+	sources := make(map[string]string)
+	warns := &warnings{asErrors: true}
+	configurations := mergeTLSConfigurations(nil, sources, "a.toml", []*tls.Configuration{first}, warns)
+	configurations = mergeTLSConfigurations(configurations, sources, "b.toml", []*tls.Configuration{duplicate, other}, warns)
+
+	assert.Equal(t, []*tls.Configuration{first, other}, configurations)
+	assert.Equal(t, map[string]string{
+		tlsConfigurationKey(first): "a.toml",
+		tlsConfigurationKey(other): "b.toml",
+	}, sources)
+	assert.Equal(t, []string{"TLS configuration of entry points [https] of b.toml already configured in a.toml, skipping"}, warns.messages)
+}
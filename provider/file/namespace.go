@@ -0,0 +1,63 @@
+package file
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/containous/traefik/types"
+)
+
+// invalidNamespaceCharacters matches the characters replaced in the namespaces derived from the file paths.
+var invalidNamespaceCharacters = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// fileNamespace returns the prefix of the frontends and backends of the file with NamespaceByFile:
+// its path relative to its configured directory, or to the Archive for its members, or its name outside of the directories,
+// without extensions, and with the separators and other special characters replaced by dashes, such as "sub-web" for "sub/web.toml".
+func (p *Provider) fileNamespace(filename string) string {
+	name := filepath.Base(filename)
+	root := p.rootDirectory(filename)
+	if root == "" && p.readsArchive() {
+		root = p.Archive
+	}
+	if root != "" {
+		if relativePath, err := filepath.Rel(root, filename); err == nil {
+			name = relativePath
+		}
+	}
+
+	name = strings.TrimSuffix(uncompressedName(name), templateExtension)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.Trim(invalidNamespaceCharacters.ReplaceAllString(name, "-"), "-")
+}
+
+// namespacedName returns the name prefixed with the namespace, or the name itself without namespace.
+func namespacedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "-" + name
+}
+
+// namespaceFrontend returns a copy of the frontend whose references to the backends defined along with it,
+// by the frontend itself or by its error pages, are prefixed with the namespace.
+// The references to the backends of other files are kept unchanged.
+func namespaceFrontend(namespace string, frontend *types.Frontend, backends map[string]*types.Backend) *types.Frontend {
+	namespaced := *frontend
+	if _, ok := backends[frontend.Backend]; ok {
+		namespaced.Backend = namespacedName(namespace, frontend.Backend)
+	}
+
+	if len(frontend.Errors) > 0 {
+		namespaced.Errors = make(map[string]*types.ErrorPage, len(frontend.Errors))
+		for errorName, errorPage := range frontend.Errors {
+			if _, ok := backends[errorPage.Backend]; ok {
+				page := *errorPage
+				page.Backend = namespacedName(namespace, errorPage.Backend)
+				errorPage = &page
+			}
+			namespaced.Errors[errorName] = errorPage
+		}
+	}
+	return &namespaced
+}
@@ -0,0 +1,134 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNamespace(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filename string
+		expected string
+	}{
+		{
+			desc:     "file of the directory",
+			filename: "/etc/traefik/rules/web.toml",
+			expected: "web",
+		},
+		{
+			desc:     "file of a sub-directory",
+			filename: "/etc/traefik/rules/team-a/web.yml",
+			expected: "team-a-web",
+		},
+		{
+			desc:     "compressed template",
+			filename: "/etc/traefik/rules/web.toml.tmpl.gz",
+			expected: "web",
+		},
+		{
+			desc:     "special characters",
+			filename: "/etc/traefik/rules/my web.v2.json",
+			expected: "my-web-v2",
+		},
+		{
+			desc:     "file outside of the directories",
+			filename: "/etc/traefik/api.toml",
+			expected: "api",
+		},
+	}
+
+	pvd := &Provider{Directory: "/etc/traefik/rules"}
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, pvd.fileNamespace(test.filename))
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryNamespaceByFile(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	content := `
+[backends.backend1.servers.server1]
+url = "http://%s:80"
+
+[frontends.frontend1]
+backend = "backend1"
+  [frontends.frontend1.errors.network]
+  status = ["500-599"]
+  backend = "backend1"
+  [frontends.frontend1.errors.shared]
+  status = ["404"]
+  backend = "errors"
+`
+	createFile(t, tempDir, "web.toml", fmt.Sprintf(content, "10.0.0.1"))
+	teamDir := createSubDir(t, tempDir, "team-a")
+	createFile(t, teamDir, "web.toml", fmt.Sprintf(content, "10.0.1.1"))
+
+	pvd := &Provider{Directory: tempDir, NamespaceByFile: true, WarningsAsErrors: true}
+	configuration, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	require.Len(t, configuration.Backends, 2)
+	require.Contains(t, configuration.Backends, "web-backend1")
+	require.Contains(t, configuration.Backends, "team-a-web-backend1")
+	assert.Equal(t, "http://10.0.1.1:80", configuration.Backends["team-a-web-backend1"].Servers["server1"].URL)
+
+	require.Len(t, configuration.Frontends, 2)
+	for _, namespace := range []string{"web", "team-a-web"} {
+		frontend := configuration.Frontends[namespace+"-frontend1"]
+		require.NotNil(t, frontend, namespace)
+		assert.Equal(t, namespace+"-backend1", frontend.Backend)
+		assert.Equal(t, namespace+"-backend1", frontend.Errors["network"].Backend)
+		// The backends of other files are referenced by their full name
+		assert.Equal(t, "errors", frontend.Errors["shared"].Backend)
+	}
+
+	assert.Equal(t, filepath.Join(teamDir, "web.toml"), pvd.BackendFile("team-a-web-backend1"))
+}
+
+func TestBuildConfigurationArchiveNamespaceByFile(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	archive := filepath.Join(tempDir, "rules.tar.gz")
+	createArchive(t, archive, []archiveMember{
+		{name: "prod/"},
+		{name: "prod/api.toml", content: []byte(createBackendConfiguration(1))},
+		{name: "staging/"},
+		{name: "staging/api.toml", content: []byte(createBackendConfiguration(1))},
+	})
+
+	pvd := &Provider{Archive: archive, NamespaceByFile: true, WarningsAsErrors: true}
+	configuration, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"prod-api-backend1", "staging-api-backend1"}, backendNames(configuration))
+	assert.Equal(t, filepath.Join(archive, "prod", "api.toml"), pvd.BackendFile("prod-api-backend1"))
+}
+
+func TestNamespaceFrontendKeepsCachedFrontend(t *testing.T) {
+	frontend := &types.Frontend{
+		Backend: "backend1",
+		Errors:  map[string]*types.ErrorPage{"network": {Backend: "backend1"}},
+	}
+	backends := map[string]*types.Backend{"backend1": {}}
+
+	namespaced := namespaceFrontend("web", frontend, backends)
+
+	assert.Equal(t, "web-backend1", namespaced.Backend)
+	assert.Equal(t, "web-backend1", namespaced.Errors["network"].Backend)
+	assert.Equal(t, "backend1", frontend.Backend)
+	assert.Equal(t, "backend1", frontend.Errors["network"].Backend)
+}
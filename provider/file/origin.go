@@ -0,0 +1,68 @@
+package file
+
+import (
+	"sort"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+)
+
+// configurationOrigins holds the file defining each frontend and backend of a configuration, by name.
+type configurationOrigins struct {
+	frontends map[string]string
+	backends  map[string]string
+}
+
+// newConfigurationOrigins returns the origins of a configuration whose frontends and backends are all defined by the file.
+func newConfigurationOrigins(filename string, configuration *types.Configuration) *configurationOrigins {
+	origins := &configurationOrigins{
+		frontends: make(map[string]string, len(configuration.Frontends)),
+		backends:  make(map[string]string, len(configuration.Backends)),
+	}
+	for frontendName := range configuration.Frontends {
+		origins.frontends[frontendName] = filename
+	}
+	for backendName := range configuration.Backends {
+		origins.backends[backendName] = filename
+	}
+	return origins
+}
+
+// setOrigins records the origins of the last configuration loaded, and logs them at the debug level.
+func (p *Provider) setOrigins(origins *configurationOrigins) {
+	p.origins.Set(origins)
+
+	for _, frontendName := range sortedKeys(origins.frontends) {
+		log.Debugf("Frontend %s defined in %s", frontendName, origins.frontends[frontendName])
+	}
+	for _, backendName := range sortedKeys(origins.backends) {
+		log.Debugf("Backend %s defined in %s", backendName, origins.backends[backendName])
+	}
+}
+
+// FrontendFile returns the file defining the frontend in the last configuration loaded, or an empty string if none does.
+// With the merge strategy, it is the first file loaded, whose definition takes precedence.
+func (p *Provider) FrontendFile(name string) string {
+	if origins, ok := p.origins.Get().(*configurationOrigins); ok {
+		return origins.frontends[name]
+	}
+	return ""
+}
+
+// BackendFile returns the file defining the backend in the last configuration loaded, or an empty string if none does.
+// With the merge strategy, it is the first file loaded, whose definition takes precedence.
+func (p *Provider) BackendFile(name string) string {
+	if origins, ok := p.origins.Get().(*configurationOrigins); ok {
+		return origins.backends[name]
+	}
+	return ""
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
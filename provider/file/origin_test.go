@@ -0,0 +1,67 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationOrigins(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+	includeDir := createTempDir(t, "testinclude")
+	defer os.RemoveAll(includeDir)
+
+	createFile(t, includeDir, "common.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
+	createFile(t, tempDir, "a.toml",
+		fmt.Sprintf("[include]\nfiles = [%q]\n", filepath.Join(includeDir, "common.toml")),
+		createFrontendConfiguration(1),
+		backendWithURL("backend1", "http://172.17.0.1:80"))
+	createFile(t, tempDir, "b.toml",
+		backendWithURL("backend1", "http://172.17.0.2:80"),
+		backendWithURL("backend2", "http://172.17.0.2:80"))
+
+	testCases := []struct {
+		desc     string
+		strategy string
+		expected map[string]string
+	}{
+		{
+			desc:     "skip",
+			strategy: mergeStrategySkip,
+			expected: map[string]string{
+				"backend1": filepath.Join(tempDir, "a.toml"),
+				"backend2": filepath.Join(tempDir, "b.toml"),
+				"backend3": filepath.Join(includeDir, "common.toml"),
+			},
+		},
+		{
+			desc:     "replace",
+			strategy: mergeStrategyReplace,
+			expected: map[string]string{
+				"backend1": filepath.Join(tempDir, "b.toml"),
+				"backend2": filepath.Join(tempDir, "b.toml"),
+				"backend3": filepath.Join(includeDir, "common.toml"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			pvd := &Provider{Directory: tempDir, MergeStrategy: test.strategy}
+			configuration, err := pvd.BuildConfiguration()
+			require.NoError(t, err)
+
+			for backendName := range configuration.Backends {
+				assert.Equal(t, test.expected[backendName], pvd.BackendFile(backendName), backendName)
+			}
+			assert.Equal(t, filepath.Join(tempDir, "a.toml"), pvd.FrontendFile("frontend1"))
+			assert.Empty(t, pvd.BackendFile("missing"))
+		})
+	}
+}
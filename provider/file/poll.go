@@ -0,0 +1,120 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"gopkg.in/fsnotify.v1"
+)
+
+// polledFile is the state of a polled file, compared between two polls to detect its changes.
+type polledFile struct {
+	modTime time.Time
+	size    int64
+}
+
+// pollFiles checks the watched directories and files for changes every PollInterval, instead of watching them,
+// for the file systems on which the file system notifications are not reliable, such as some bind-mounted volumes.
+// The files created, modified or removed between two polls produce the same reloads as their events would.
+func (p *Provider) pollFiles(pool *safe.Pool, configurationChan chan<- types.ConfigMessage) error {
+	if p.PollInterval <= 0 {
+		return errors.New("the poll interval of the configuration files must be positive")
+	}
+
+	last, err := p.pollSnapshot()
+	if err != nil {
+		return err
+	}
+
+	pool.Go(func(stop chan bool) {
+		ticker := time.NewTicker(time.Duration(p.PollInterval))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				current, err := p.pollSnapshot()
+				if err != nil {
+					log.Errorf("Unable to poll the configuration files: %v", err)
+					continue
+				}
+
+				events := p.pollEvents(last, current)
+				last = current
+				if len(events) == 0 {
+					continue
+				}
+				event := events[0]
+				for _, next := range events[1:] {
+					event = p.coalesceEvents(event, next)
+				}
+				p.watcherCallback(configurationChan, event, stop)
+			}
+		}
+	})
+	return nil
+}
+
+// pollSnapshot returns the state of the files of the watched directories, by path.
+// The watched directories are listed again on each poll, so that new sub-directories are polled as well.
+func (p *Provider) pollSnapshot() (map[string]polledFile, error) {
+	watchItems, err := p.watchedDirectories()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make(map[string]polledFile)
+	for _, watchItem := range watchItems {
+		fileInfo, err := os.Stat(watchItem)
+		if err != nil {
+			// A removed item is reported by the removal of its files
+			continue
+		}
+		if !fileInfo.IsDir() {
+			snapshot[filepath.Clean(watchItem)] = polledFile{modTime: fileInfo.ModTime(), size: fileInfo.Size()}
+			continue
+		}
+
+		fileList, err := p.listDirectory(watchItem)
+		if err != nil {
+			log.Debugf("Unable to poll directory %s: %v", watchItem, err)
+			continue
+		}
+		for _, item := range fileList {
+			snapshot[filepath.Join(watchItem, item.Name())] = polledFile{modTime: item.ModTime(), size: item.Size()}
+		}
+	}
+	return snapshot, nil
+}
+
+// pollEvents returns the events of the watched paths created, modified or removed between the snapshots.
+func (p *Provider) pollEvents(last, current map[string]polledFile) []fsnotify.Event {
+	var events []fsnotify.Event
+	addEvent := func(evt fsnotify.Event) {
+		if p.isWatchedEvent(evt) {
+			events = append(events, evt)
+		}
+	}
+
+	for name, state := range current {
+		lastState, ok := last[name]
+		if !ok {
+			addEvent(fsnotify.Event{Name: name, Op: fsnotify.Create})
+		} else if !state.modTime.Equal(lastState.modTime) || state.size != lastState.size {
+			addEvent(fsnotify.Event{Name: name, Op: fsnotify.Write})
+		}
+	}
+	for name := range last {
+		if _, ok := current[name]; !ok {
+			addEvent(fsnotify.Event{Name: name, Op: fsnotify.Remove})
+		}
+	}
+	return events
+}
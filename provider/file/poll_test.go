@@ -0,0 +1,150 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/fsnotify.v1"
+)
+
+func TestProvideDirectoryAndForcePoll(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{
+		Directory:    tempDir,
+		ProviderName: "file",
+		ForcePoll:    true,
+		PollInterval: flaeg.Duration(50 * time.Millisecond),
+	}
+	pvd.Watch = true
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	require.NoError(t, pvd.Provide(configurationChan, pool, nil))
+	assert.Len(t, receiveConfiguration(t, configurationChan).Backends, 2)
+
+	createFile(t, tempDir, "frontends.toml", createFrontendConfiguration(2))
+	assert.Len(t, receiveConfiguration(t, configurationChan).Frontends, 2)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(3))
+	assert.Len(t, receiveConfiguration(t, configurationChan).Backends, 3)
+
+	require.NoError(t, os.Remove(filepath.Join(tempDir, "frontends.toml")))
+	assert.Len(t, receiveConfiguration(t, configurationChan).Frontends, 0)
+}
+
+func TestProvideSingleFilePollFallback(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	// The directory of the file can not be watched until it is created
+	configDir := filepath.Join(tempDir, "config")
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{
+		ProviderName:            "file",
+		AllowEmptyConfiguration: true,
+		PollInterval:            flaeg.Duration(50 * time.Millisecond),
+	}
+	pvd.Filename = filepath.Join(configDir, "rules.toml")
+	pvd.Watch = true
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	require.NoError(t, pvd.Provide(configurationChan, pool, nil))
+	assert.Len(t, receiveConfiguration(t, configurationChan).Backends, 0)
+
+	createSubDir(t, tempDir, "config")
+	createFile(t, configDir, "rules.toml", createBackendConfiguration(2))
+	assert.Len(t, receiveConfiguration(t, configurationChan).Backends, 2)
+}
+
+func TestProvideSingleFileWithoutPollFallback(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	pvd := &Provider{ProviderName: "file"}
+	pvd.Filename = filepath.Join(tempDir, "config", "rules.toml")
+	pvd.Watch = true
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	err := pvd.Provide(make(chan types.ConfigMessage, 10), pool, nil)
+	assert.Error(t, err)
+}
+
+func TestProvideForcePollInvalidInterval(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	pvd := &Provider{Directory: tempDir, ProviderName: "file", AllowEmptyConfiguration: true, ForcePoll: true}
+	pvd.Watch = true
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	err := pvd.Provide(make(chan types.ConfigMessage, 10), pool, nil)
+	assert.EqualError(t, err, "the poll interval of the configuration files must be positive")
+}
+
+func TestPollEvents(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	now := time.Now()
+	last := map[string]polledFile{
+		filepath.Join(tempDir, "modified.toml"):  {modTime: now, size: 10},
+		filepath.Join(tempDir, "resized.toml"):   {modTime: now, size: 10},
+		filepath.Join(tempDir, "unchanged.toml"): {modTime: now, size: 10},
+		filepath.Join(tempDir, "removed.toml"):   {modTime: now, size: 10},
+		filepath.Join(tempDir, "notes.txt"):      {modTime: now, size: 10},
+	}
+	current := map[string]polledFile{
+		filepath.Join(tempDir, "modified.toml"):  {modTime: now.Add(time.Second), size: 10},
+		filepath.Join(tempDir, "resized.toml"):   {modTime: now, size: 20},
+		filepath.Join(tempDir, "unchanged.toml"): {modTime: now, size: 10},
+		filepath.Join(tempDir, "created.toml"):   {modTime: now, size: 10},
+		filepath.Join(tempDir, "notes.txt"):      {modTime: now.Add(time.Second), size: 10},
+	}
+
+	pvd := &Provider{Directory: tempDir}
+	ops := make(map[string]fsnotify.Op)
+	for _, evt := range pvd.pollEvents(last, current) {
+		ops[filepath.Base(evt.Name)] = evt.Op
+	}
+
+	expected := map[string]fsnotify.Op{
+		"modified.toml": fsnotify.Write,
+		"resized.toml":  fsnotify.Write,
+		"created.toml":  fsnotify.Create,
+		"removed.toml":  fsnotify.Remove,
+	}
+	assert.Equal(t, expected, ops)
+}
+
+func receiveConfiguration(t *testing.T, configurationChan chan types.ConfigMessage) *types.Configuration {
+	t.Helper()
+
+	select {
+	case msg := <-configurationChan:
+		return msg.Configuration
+	case <-time.After(2 * time.Second):
+		t.Fatal("configuration not sent")
+		return nil
+	}
+}
@@ -0,0 +1,65 @@
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containous/traefik/types"
+	"github.com/ghodss/yaml"
+)
+
+// PreviewConfiguration returns the configuration as it would be sent: loaded, merged, rendered and validated,
+// without sending it. It can be called while the provider is started, its reloads waiting for the preview to be built.
+func (p *Provider) PreviewConfiguration() (*types.Configuration, error) {
+	p.reloadLock.Lock()
+	defer p.reloadLock.Unlock()
+
+	return p.BuildConfiguration()
+}
+
+// MarshalConfiguration encodes the configuration in the format named "toml", "yaml" or "json",
+// so that a previewed configuration can be written back to a configuration file.
+func MarshalConfiguration(configuration *types.Configuration, formatName string) ([]byte, error) {
+	f, ok := formatFromName(formatName)
+	if !ok {
+		return nil, fmt.Errorf("unsupported configuration format %q", formatName)
+	}
+
+	switch f {
+	case formatTOML:
+		var buffer bytes.Buffer
+		if err := toml.NewEncoder(&buffer).Encode(configuration); err != nil {
+			return nil, fmt.Errorf("unable to encode the configuration to %s: %v", f, err)
+		}
+		return buffer.Bytes(), nil
+	case formatYAML:
+		// Convert from JSON so that the json tags of types.Configuration are honored, as when decoding
+		content, err := json.Marshal(configuration)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode the configuration to %s: %v", f, err)
+		}
+		return yaml.JSONToYAML(content)
+	default:
+		content, err := json.MarshalIndent(configuration, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode the configuration to %s: %v", f, err)
+		}
+		return content, nil
+	}
+}
+
+// formatFromName returns the format of the name, case-insensitively, and false if it is not a supported one.
+func formatFromName(name string) (format, bool) {
+	switch strings.ToLower(name) {
+	case "toml":
+		return formatTOML, true
+	case "yaml", "yml":
+		return formatYAML, true
+	case "json":
+		return formatJSON, true
+	}
+	return "", false
+}
@@ -0,0 +1,75 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/provider"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewConfigurationRoundTrip(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		formatName string
+		filename   string
+	}{
+		{
+			desc:       "TOML",
+			formatName: "toml",
+			filename:   "preview.toml",
+		},
+		{
+			desc:       "YAML",
+			formatName: "YAML",
+			filename:   "preview.yml",
+		},
+		{
+			desc:       "JSON",
+			formatName: "json",
+			filename:   "preview.json",
+		},
+	}
+
+	tempDir := createTempDir(t, "testpreview")
+	defer os.RemoveAll(tempDir)
+
+	rulesDir := createSubDir(t, tempDir, "rules")
+	createFile(t, rulesDir, "backends.toml", createBackendConfiguration(2))
+	createFile(t, rulesDir, "frontends.toml", createFrontendConfiguration(2)+`
+  [frontends.frontend1.routes.route1]
+  rule = "Host:test.localhost"
+  [frontends.frontend1.headers]
+  SSLRedirect = true
+`)
+	createFile(t, rulesDir, "tls.toml", createTLSConfiguration(1))
+
+	pvd := &Provider{Directory: rulesDir}
+	preview, err := pvd.PreviewConfiguration()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend1", "backend2"}, backendNames(preview))
+	assert.Equal(t, []string{"frontend1", "frontend2"}, frontendNames(preview))
+	assert.Len(t, preview.TLSConfiguration, 1)
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			content, err := MarshalConfiguration(preview, test.formatName)
+			require.NoError(t, err)
+
+			previewFile := createFile(t, tempDir, test.filename, string(content))
+
+			loaded, err := (&Provider{BaseProvider: provider.BaseProvider{Filename: previewFile.Name()}}).BuildConfiguration()
+			require.NoError(t, err)
+			assert.Equal(t, preview, loaded)
+		})
+	}
+}
+
+func TestMarshalConfigurationUnsupportedFormat(t *testing.T) {
+	_, err := MarshalConfiguration(&types.Configuration{}, "xml")
+	require.Error(t, err)
+	assert.Equal(t, `unsupported configuration format "xml"`, err.Error())
+}
@@ -0,0 +1,30 @@
+package file
+
+import (
+	"github.com/containous/traefik/types"
+)
+
+// applyProfile overlays the frontends, backends and TLS configurations of the ActiveProfile of the file
+// over its base definitions, the ones of the profile replacing the base ones with the same name.
+// The profiles are dropped from the file content once applied.
+func (p *Provider) applyProfile(fc *fileContent) {
+	profile := fc.Profiles[p.ActiveProfile]
+	fc.Profiles = nil
+	if p.ActiveProfile == "" || profile == nil {
+		return
+	}
+
+	if fc.Backends == nil {
+		fc.Backends = make(map[string]*types.Backend)
+	}
+	for backendName, backend := range profile.Backends {
+		fc.Backends[backendName] = backend
+	}
+	if fc.Frontends == nil {
+		fc.Frontends = make(map[string]*types.Frontend)
+	}
+	for frontendName, frontend := range profile.Frontends {
+		fc.Frontends[frontendName] = frontend
+	}
+	fc.TLSConfiguration = append(fc.TLSConfiguration, profile.TLSConfiguration...)
+}
@@ -0,0 +1,113 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProfile(t *testing.T) {
+	tomlContent := `
+[backends.backend1.servers.server1]
+url = "http://172.17.0.1:80"
+[backends.backend2.servers.server1]
+url = "http://172.17.0.2:80"
+
+[profiles.prod.backends.backend1.servers.server1]
+url = "http://10.0.0.1:80"
+[profiles.prod.backends.backend3.servers.server1]
+url = "http://10.0.0.3:80"
+`
+	yamlContent := `
+backends:
+  backend1:
+    servers:
+      server1:
+        url: http://172.17.0.1:80
+  backend2:
+    servers:
+      server1:
+        url: http://172.17.0.2:80
+profiles:
+  prod:
+    backends:
+      backend1:
+        servers:
+          server1:
+            url: http://10.0.0.1:80
+      backend3:
+        servers:
+          server1:
+            url: http://10.0.0.3:80
+`
+
+	testCases := []struct {
+		desc          string
+		filename      string
+		content       string
+		activeProfile string
+		expected      map[string]string
+	}{
+		{
+			desc:     "no active profile",
+			filename: "rules.toml",
+			content:  tomlContent,
+			expected: map[string]string{
+				"backend1": "http://172.17.0.1:80",
+				"backend2": "http://172.17.0.2:80",
+			},
+		},
+		{
+			desc:          "active profile",
+			filename:      "rules.toml",
+			content:       tomlContent,
+			activeProfile: "prod",
+			expected: map[string]string{
+				"backend1": "http://10.0.0.1:80",
+				"backend2": "http://172.17.0.2:80",
+				"backend3": "http://10.0.0.3:80",
+			},
+		},
+		{
+			desc:          "active profile not defined by the file",
+			filename:      "rules.toml",
+			content:       tomlContent,
+			activeProfile: "staging",
+			expected: map[string]string{
+				"backend1": "http://172.17.0.1:80",
+				"backend2": "http://172.17.0.2:80",
+			},
+		},
+		{
+			desc:          "active profile in YAML",
+			filename:      "rules.yml",
+			content:       yamlContent,
+			activeProfile: "prod",
+			expected: map[string]string{
+				"backend1": "http://10.0.0.1:80",
+				"backend2": "http://172.17.0.2:80",
+				"backend3": "http://10.0.0.3:80",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pvd := &Provider{ActiveProfile: test.activeProfile}
+			fc, err := pvd.decodeContent(test.filename, []byte(test.content))
+			require.NoError(t, err)
+
+			assert.Nil(t, fc.Profiles)
+
+			urls := make(map[string]string)
+			for name, backend := range fc.Backends {
+				urls[name] = backend.Servers["server1"].URL
+			}
+			assert.Equal(t, test.expected, urls)
+		})
+	}
+}
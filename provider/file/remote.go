@@ -0,0 +1,180 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+)
+
+// remoteClient is the client fetching the remote configuration.
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// remoteContent is the last content fetched from the RemoteURL, along with its validators.
+type remoteContent struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// loadRemoteConfiguration fetches and decodes the configuration of the RemoteURL.
+func (p *Provider) loadRemoteConfiguration() (*types.Configuration, error) {
+	configuration, _, err := p.fetchRemoteConfiguration(false)
+	return configuration, err
+}
+
+// fetchRemoteConfiguration fetches and decodes the configuration of the RemoteURL.
+// If conditional, the validators of the last content fetched are sent,
+// and it returns false when the remote content did not change.
+func (p *Provider) fetchRemoteConfiguration(conditional bool) (*types.Configuration, bool, error) {
+	last, _ := p.remoteContent.Get().(*remoteContent)
+	if !conditional {
+		last = nil
+	}
+
+	content, err := fetchRemoteContent(p.RemoteURL, last, p.MaxFileSize)
+	if err != nil {
+		return nil, false, fmt.Errorf("error fetching configuration %s: %v", p.RemoteURL, err)
+	}
+	if last != nil && bytes.Equal(content.body, last.body) {
+		p.remoteContent.Set(content)
+		return nil, false, nil
+	}
+
+	// The format and template extensions are the ones of the path of the URL
+	remoteURL, err := url.Parse(p.RemoteURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	fc, err := p.decodeContent(remoteURL.Path, content.body)
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading configuration %s: %v", p.RemoteURL, err)
+	}
+	if len(fc.Include.Files) > 0 {
+		return nil, false, fmt.Errorf("error reading configuration %s: includes are not supported in remote configurations", p.RemoteURL)
+	}
+	warns := p.newWarnings()
+	warnDuplicateServers(p.RemoteURL, &fc.Configuration, warns)
+	if err := warns.err(); err != nil {
+		return nil, false, fmt.Errorf("error reading configuration %s: %v", p.RemoteURL, err)
+	}
+	p.setOrigins(newConfigurationOrigins(p.RemoteURL, &fc.Configuration))
+
+	// The validators are only kept once the content is decoded, so that an invalid content is fetched again
+	p.remoteContent.Set(content)
+
+	return &fc.Configuration, true, nil
+}
+
+// fetchRemoteContent downloads the content of the URL, failing if it is larger than maxSize bytes when maxSize is positive.
+// If last is not nil, the request is conditional, and last is returned when the server reports the content did not change.
+func fetchRemoteContent(remoteURL string, last *remoteContent, maxSize int64) (*remoteContent, error) {
+	req, err := http.NewRequest(http.MethodGet, remoteURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		if last.etag != "" {
+			req.Header.Set("If-None-Match", last.etag)
+		}
+		if last.lastModified != "" {
+			req.Header.Set("If-Modified-Since", last.lastModified)
+		}
+	}
+
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && last != nil:
+		return last, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	reader := io.Reader(resp.Body)
+	if maxSize > 0 {
+		reader = io.LimitReader(resp.Body, maxSize+1)
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("the content is larger than the maximum file size of %d bytes", maxSize)
+	}
+
+	return &remoteContent{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		body:         body,
+	}, nil
+}
+
+// pollRemote fetches the remote configuration every RemotePollInterval, and sends it when it changed.
+func (p *Provider) pollRemote(pool *safe.Pool, configurationChan chan<- types.ConfigMessage) error {
+	if p.RemotePollInterval <= 0 {
+		return errors.New("the poll interval of the remote configuration must be positive")
+	}
+
+	pool.Go(func(stop chan bool) {
+		ticker := time.NewTicker(time.Duration(p.RemotePollInterval))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.remoteCallback(configurationChan)
+			}
+		}
+	})
+	return nil
+}
+
+func (p *Provider) remoteCallback(configurationChan chan<- types.ConfigMessage) {
+	p.reloadLock.Lock()
+	defer p.reloadLock.Unlock()
+
+	if p.skipsReload() {
+		return
+	}
+	p.reloadRemote(configurationChan, true, triggerWatch)
+}
+
+// reloadRemote fetches the remote configuration again and sends it if it changed.
+func (p *Provider) reloadRemote(configurationChan chan<- types.ConfigMessage, conditional bool, trigger string) {
+	configuration, changed, err := p.fetchRemoteConfiguration(conditional)
+	if err == nil && changed {
+		configuration, err = p.finalizeConfiguration(configuration)
+	}
+
+	if err != nil {
+		log.Errorf("Error occurred during remote configuration poll: %s", err)
+		p.markStale()
+		return
+	}
+	if !changed {
+		return
+	}
+	if p.isLastConfiguration(configuration) {
+		log.Debugf("Configuration unchanged after the change of %s, skipping", p.RemoteURL)
+		p.resetStale()
+		return
+	}
+
+	p.sendConfigToChannel(configurationChan, configuration, trigger)
+}
@@ -0,0 +1,243 @@
+package file
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchRemoteConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		path            string
+		body            string
+		etag            string
+		lastModified    string
+		maxFileSize     int64
+		expectedChanged bool
+		expectedError   bool
+	}{
+		{
+			desc:            "TOML configuration",
+			path:            "/traefik.toml",
+			body:            createFrontendConfiguration(1) + createBackendConfiguration(1),
+			expectedChanged: true,
+		},
+		{
+			desc:            "YAML configuration",
+			path:            "/traefik.yml",
+			body:            createYAMLFrontendConfiguration(1) + createYAMLBackendConfiguration(1),
+			expectedChanged: true,
+		},
+		{
+			desc:            "configuration without extension",
+			path:            "/config",
+			body:            createFrontendConfiguration(1) + createBackendConfiguration(1),
+			expectedChanged: true,
+		},
+		{
+			desc:          "missing configuration",
+			path:          "/missing.toml",
+			expectedError: true,
+		},
+		{
+			desc:          "invalid configuration",
+			path:          "/traefik.toml",
+			body:          "[backends",
+			expectedError: true,
+		},
+		{
+			desc:            "configuration smaller than the maximum file size",
+			path:            "/traefik.toml",
+			body:            createFrontendConfiguration(1) + createBackendConfiguration(1),
+			maxFileSize:     1024,
+			expectedChanged: true,
+		},
+		{
+			desc:          "configuration larger than the maximum file size",
+			path:          "/traefik.toml",
+			body:          createFrontendConfiguration(1) + createBackendConfiguration(1),
+			maxFileSize:   64,
+			expectedError: true,
+		},
+		{
+			desc:          "configuration with includes",
+			path:          "/traefik.toml",
+			body:          "[include]\nfiles = [\"other.toml\"]\n",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if test.body == "" {
+					http.NotFound(rw, req)
+					return
+				}
+				rw.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			pvd := &Provider{RemoteURL: server.URL + test.path, MaxFileSize: test.maxFileSize}
+			configuration, changed, err := pvd.fetchRemoteConfiguration(false)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedChanged, changed)
+			assert.Len(t, configuration.Frontends, 1)
+			assert.Len(t, configuration.Backends, 1)
+		})
+	}
+}
+
+func TestFetchRemoteConfigurationConditional(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		handler         func(body *string) http.HandlerFunc
+		changeBody      bool
+		expectedChanged bool
+	}{
+		{
+			desc: "ETag not modified",
+			handler: func(body *string) http.HandlerFunc {
+				return func(rw http.ResponseWriter, req *http.Request) {
+					if req.Header.Get("If-None-Match") == `"v1"` {
+						rw.WriteHeader(http.StatusNotModified)
+						return
+					}
+					rw.Header().Set("ETag", `"v1"`)
+					rw.Write([]byte(*body))
+				}
+			},
+		},
+		{
+			desc: "Last-Modified not modified",
+			handler: func(body *string) http.HandlerFunc {
+				lastModified := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+				return func(rw http.ResponseWriter, req *http.Request) {
+					if req.Header.Get("If-Modified-Since") == lastModified {
+						rw.WriteHeader(http.StatusNotModified)
+						return
+					}
+					rw.Header().Set("Last-Modified", lastModified)
+					rw.Write([]byte(*body))
+				}
+			},
+		},
+		{
+			desc: "same content without validators",
+			handler: func(body *string) http.HandlerFunc {
+				return func(rw http.ResponseWriter, req *http.Request) {
+					rw.Write([]byte(*body))
+				}
+			},
+		},
+		{
+			desc: "changed content without validators",
+			handler: func(body *string) http.HandlerFunc {
+				return func(rw http.ResponseWriter, req *http.Request) {
+					rw.Write([]byte(*body))
+				}
+			},
+			changeBody:      true,
+			expectedChanged: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			body := createFrontendConfiguration(1) + createBackendConfiguration(1)
+			server := httptest.NewServer(test.handler(&body))
+			defer server.Close()
+
+			pvd := &Provider{RemoteURL: server.URL + "/traefik.toml"}
+			_, changed, err := pvd.fetchRemoteConfiguration(true)
+			require.NoError(t, err)
+			require.True(t, changed)
+
+			if test.changeBody {
+				body = createFrontendConfiguration(2) + createBackendConfiguration(2)
+			}
+
+			_, changed, err = pvd.fetchRemoteConfiguration(true)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedChanged, changed)
+		})
+	}
+}
+
+func TestFetchRemoteConfigurationConditionalInvalid(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		rw.Header().Set("ETag", `"v1"`)
+		rw.Write([]byte("[backends"))
+	}))
+	defer server.Close()
+
+	pvd := &Provider{RemoteURL: server.URL + "/traefik.toml"}
+	_, _, err := pvd.fetchRemoteConfiguration(true)
+	require.Error(t, err)
+
+	// The invalid content is fetched and decoded again, instead of being reported as not modified
+	_, _, err = pvd.fetchRemoteConfiguration(true)
+	require.Error(t, err)
+}
+
+func TestProvideRemoteAndWatch(t *testing.T) {
+	var lock sync.Mutex
+	body := createFrontendConfiguration(2) + createBackendConfiguration(2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		rw.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	expectedNumFrontends := 2
+	expectedNumBackends := 2
+	expectedNumTLSConf := 0
+
+	configurationChan, signal := createConfigurationRoutine(t, &expectedNumFrontends, &expectedNumBackends, &expectedNumTLSConf)
+
+	stop := provide(configurationChan, watch, func(pvd *Provider) {
+		pvd.RemoteURL = server.URL + "/traefik.toml"
+		pvd.RemotePollInterval = flaeg.Duration(100 * time.Millisecond)
+	})
+	defer stop()
+
+	err := waitForSignal(signal, 2*time.Second, "remote config")
+	require.NoError(t, err)
+
+	// The unchanged configuration is not sent again
+	err = waitForSignal(signal, 300*time.Millisecond, "unchanged remote config")
+	assert.Error(t, err)
+
+	expectedNumFrontends = 3
+	expectedNumBackends = 3
+	lock.Lock()
+	body = createFrontendConfiguration(3) + createBackendConfiguration(3)
+	lock.Unlock()
+
+	err = waitForSignal(signal, 2*time.Second, "changed remote config")
+	assert.NoError(t, err)
+}
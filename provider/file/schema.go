@@ -0,0 +1,58 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// loadSchema reads the SchemaFile, against which each configuration file is validated.
+func (p *Provider) loadSchema() error {
+	if p.SchemaFile == "" {
+		p.schema.Set(nil)
+		return nil
+	}
+
+	content, err := readFile(p.SchemaFile)
+	if err != nil {
+		return fmt.Errorf("error reading schema file %s: %v", p.SchemaFile, err)
+	}
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(content))
+	if err != nil {
+		return fmt.Errorf("error reading schema file %s: %v", p.SchemaFile, err)
+	}
+
+	p.schema.Set(schema)
+	return nil
+}
+
+// validateSchema validates the content, decoded in the given format, against the schema of the SchemaFile if any.
+// The mismatches are reported with the path of the offending field, such as backends.backend1.servers.server1.url.
+func (p *Provider) validateSchema(content []byte, f format) error {
+	schema, ok := p.schema.Get().(*gojsonschema.Schema)
+	if !ok {
+		return nil
+	}
+
+	values := make(map[string]interface{})
+	if err := decode(content, f, &values); err != nil {
+		return fmt.Errorf("unable to decode %s configuration: %v", f, err)
+	}
+
+	result, err := schema.Validate(gojsonschema.NewGoLoader(values))
+	if err != nil {
+		return fmt.Errorf("unable to validate the configuration against the schema %s: %v", p.SchemaFile, err)
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	var mismatches []string
+	for _, resultError := range result.Errors() {
+		field := strings.TrimPrefix(resultError.Context().String(), gojsonschema.STRING_CONTEXT_ROOT+".")
+		mismatches = append(mismatches, fmt.Sprintf("%s: %s", field, resultError.Description()))
+	}
+	return fmt.Errorf("configuration does not match the schema %s: %s", p.SchemaFile, strings.Join(mismatches, ", "))
+}
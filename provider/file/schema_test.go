@@ -0,0 +1,109 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+  "type": "object",
+  "properties": {
+    "backends": {
+      "type": "object",
+      "patternProperties": {
+        ".*": {
+          "type": "object",
+          "properties": {
+            "servers": {
+              "type": "object",
+              "patternProperties": {
+                ".*": {
+                  "type": "object",
+                  "properties": {
+                    "url": {"type": "string"},
+                    "weight": {"type": "integer"}
+                  },
+                  "required": ["url"],
+                  "additionalProperties": false
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestBuildConfigurationSchemaFile(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		content       string
+		schema        string
+		expectedError string
+	}{
+		{
+			desc:    "matching configuration",
+			content: backendWithURL("backend1", "http://172.17.0.1:80"),
+			schema:  testSchema,
+		},
+		{
+			desc: "misspelled field",
+			content: `
+[backends.backend1.servers.server1]
+url = "http://172.17.0.1:80"
+wieght = 10
+`,
+			schema:        testSchema,
+			expectedError: "backends.backend1.servers.server1: Additional property wieght is not allowed",
+		},
+		{
+			desc: "wrong type",
+			content: `
+[backends.backend1.servers.server1]
+url = "http://172.17.0.1:80"
+weight = "10"
+`,
+			schema:        testSchema,
+			expectedError: "backends.backend1.servers.server1.weight: Invalid type. Expected: integer, given: string",
+		},
+		{
+			desc:          "invalid schema",
+			content:       backendWithURL("backend1", "http://172.17.0.1:80"),
+			schema:        `{"type": 1}`,
+			expectedError: "error reading schema file",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			createFile(t, tempDir, "rules.toml", test.content)
+			createFile(t, tempDir, "schema.json", test.schema)
+
+			pvd := &Provider{
+				BaseProvider: provider.BaseProvider{Filename: filepath.Join(tempDir, "rules.toml")},
+				SchemaFile:   filepath.Join(tempDir, "schema.json"),
+			}
+			configuration, err := pvd.BuildConfiguration()
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, configuration.Backends, 1)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/containous/traefik/log"
+)
+
+// fileTooLargeError is returned for the configuration files larger than the MaxFileSize,
+// such as log files with a configuration extension, which are not read into memory.
+type fileTooLargeError struct {
+	filename     string
+	decompressed bool
+	maxSize      int64
+}
+
+func (e *fileTooLargeError) Error() string {
+	size := "it is larger"
+	if e.decompressed {
+		size = "once decompressed, it is larger"
+	}
+	return fmt.Sprintf("error reading configuration file %s: %s than the maximum file size of %d bytes", e.filename, size, e.maxSize)
+}
+
+// readConfigFile returns the content of the configuration file like readFile,
+// or a fileTooLargeError if the file, or its decompressed content, is larger than the MaxFileSize.
+func (p *Provider) readConfigFile(filename string) ([]byte, error) {
+	if p.MaxFileSize <= 0 {
+		return readFile(filename)
+	}
+	if filename == stdinFilename {
+		return readLimited(filename, stdin, p.MaxFileSize, false)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo.Size() > p.MaxFileSize {
+		return nil, &fileTooLargeError{filename: filename, maxSize: p.MaxFileSize}
+	}
+	if !isGzipFile(filename) {
+		return ioutil.ReadAll(file)
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return readLimited(filename, reader, p.MaxFileSize, true)
+}
+
+// readLimited reads the content of the reader, up to maxSize bytes, returning a fileTooLargeError if there are more.
+func readLimited(filename string, reader io.Reader, maxSize int64, decompressed bool) ([]byte, error) {
+	content, err := ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, &fileTooLargeError{filename: filename, decompressed: decompressed, maxSize: maxSize}
+	}
+	return content, nil
+}
+
+// skipTooLargeFile returns true, after logging it, if the error is a fileTooLargeError,
+// the files of the directories and archives larger than the MaxFileSize being skipped whatever SkipInvalidFiles is.
+func skipTooLargeFile(err error) bool {
+	if _, ok := err.(*fileTooLargeError); !ok {
+		return false
+	}
+	log.Warnf("Skipping configuration file: %v", err)
+	return true
+}
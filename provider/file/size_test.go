@@ -0,0 +1,110 @@
+package file
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFileConfigMaxFileSize(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		maxFileSize   int64
+		filename      string
+		content       string
+		expectedError string
+	}{
+		{
+			desc:     "unlimited",
+			filename: "rules.toml",
+			content:  createBackendConfiguration(20),
+		},
+		{
+			desc:        "smaller file",
+			maxFileSize: 1024,
+			filename:    "rules.toml",
+			content:     createBackendConfiguration(1),
+		},
+		{
+			desc:          "larger file",
+			maxFileSize:   1024,
+			filename:      "rules.toml",
+			content:       createBackendConfiguration(20),
+			expectedError: "it is larger than the maximum file size of 1024 bytes",
+		},
+		{
+			desc:          "larger once decompressed",
+			maxFileSize:   1024,
+			filename:      "rules.toml.gz",
+			content:       createBackendConfiguration(1) + "#" + strings.Repeat(" ", 2048) + "\n",
+			expectedError: "once decompressed, it is larger than the maximum file size of 1024 bytes",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testsize")
+			defer os.RemoveAll(tempDir)
+
+			filename := filepath.Join(tempDir, test.filename)
+			if isGzipFile(test.filename) {
+				createGzipFile(t, tempDir, test.filename, test.content)
+			} else {
+				createFile(t, tempDir, test.filename, test.content)
+			}
+
+			_, err := (&Provider{MaxFileSize: test.maxFileSize}).loadFileConfig(filename)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, "error reading configuration file "+filename+": "+test.expectedError, err.Error())
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryMaxFileSize(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+	createFile(t, tempDir, "access.log.toml", strings.Repeat("127.0.0.1 - - GET / 200\n", 100))
+
+	// The larger files are skipped even without SkipInvalidFiles
+	pvd := &Provider{Directory: tempDir, MaxFileSize: 1024}
+	configuration, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+	assert.Len(t, configuration.Backends, 2)
+	assert.Equal(t, []string{filepath.Join(tempDir, "backends.toml")}, pvd.LoadedFiles())
+
+	// The larger included files fail the loading
+	createFile(t, tempDir, "frontends.toml", "[include]\nfiles = [\"access.log.toml\"]\n")
+	_, err = pvd.BuildConfiguration()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "larger than the maximum file size of 1024 bytes, included by "+filepath.Join(tempDir, "frontends.toml"))
+}
+
+func TestBuildConfigurationArchiveMaxFileSize(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	archive := filepath.Join(tempDir, "rules.tar")
+	createArchive(t, archive, []archiveMember{
+		{name: "backends.toml", content: []byte(createBackendConfiguration(2))},
+		{name: "access.log.toml", content: bytes.Repeat([]byte("127.0.0.1 - - GET / 200\n"), 100)},
+	})
+
+	configuration, err := (&Provider{Archive: archive, MaxFileSize: 1024}).BuildConfiguration()
+	require.NoError(t, err)
+	assert.Len(t, configuration.Backends, 2)
+}
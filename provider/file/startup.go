@@ -0,0 +1,60 @@
+package file
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+)
+
+// provideAfterStartupDelay starts watching the configuration, then loads and sends it once the StartupDelay has elapsed,
+// so that the files still being written when Træfik starts, for example by an init container, are loaded once complete.
+// The reloads triggered meanwhile, by the changes or by ForceReload, are skipped, as the first configuration loads these changes.
+// As the provider is already started, a first configuration which can not be loaded is only logged.
+func (p *Provider) provideAfterStartupDelay(configurationChan chan<- types.ConfigMessage, pool *safe.Pool) error {
+	atomic.StoreInt32(&p.firstLoadPending, 1)
+	if err := p.watch(configurationChan, pool); err != nil {
+		atomic.StoreInt32(&p.firstLoadPending, 0)
+		return err
+	}
+
+	delay := time.Duration(p.StartupDelay)
+	log.Infof("Loading the file configuration in %s", delay)
+
+	pool.Go(func(stop chan bool) {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+		}
+
+		// The reloads waiting for the lock load the changes made while the first configuration is loaded
+		p.reloadLock.Lock()
+		defer p.reloadLock.Unlock()
+		atomic.StoreInt32(&p.firstLoadPending, 0)
+
+		start := time.Now()
+		configuration, err := p.buildInitialConfiguration()
+		if err != nil {
+			log.Errorf("Unable to load the file configuration after the startup delay: %v", err)
+			return
+		}
+		p.observeLoadDuration(configuration, triggerInitial, time.Since(start))
+		p.sendConfigToChannel(configurationChan, configuration, triggerInitial)
+	})
+	return nil
+}
+
+// skipsReload returns true if the first configuration is still waiting for the StartupDelay, in which case the reload is skipped.
+// It is called holding the reload lock.
+func (p *Provider) skipsReload() bool {
+	if atomic.LoadInt32(&p.firstLoadPending) == 0 {
+		return false
+	}
+	log.Debug("Skipping the reload of the file configuration, which is loaded after the startup delay")
+	return true
+}
@@ -0,0 +1,102 @@
+package file
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvideStartupDelay(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	// The file is still being written when the provider starts
+	createFile(t, tempDir, "backends.toml", "[backends\n")
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{
+		Directory:        tempDir,
+		ProviderName:     "file",
+		StartupDelay:     flaeg.Duration(200 * time.Millisecond),
+		DebounceDuration: flaeg.Duration(50 * time.Millisecond),
+	}
+	pvd.Watch = true
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	start := time.Now()
+	err := pvd.Provide(configurationChan, pool, nil)
+	require.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	select {
+	case msg := <-configurationChan:
+		assert.True(t, time.Since(start) >= 200*time.Millisecond, "configuration sent after %s", time.Since(start))
+		assert.Len(t, msg.Configuration.Backends, 2)
+	case <-time.After(2 * time.Second):
+		t.Fatal("configuration not sent after the startup delay")
+	}
+
+	// The change of the file during the delay is already part of the first configuration
+	time.Sleep(150 * time.Millisecond)
+	assert.Len(t, configurationChan, 0)
+}
+
+func TestProvideStartupDelayForceReload(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{Directory: tempDir, ProviderName: "file", StartupDelay: flaeg.Duration(time.Hour)}
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+	require.NoError(t, pvd.Provide(configurationChan, pool, nil))
+
+	// Neither the forced reload nor the preview wait for the startup delay
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, pvd.ForceReload())
+		pvd.Reload(configurationChan)
+
+		configuration, err := pvd.PreviewConfiguration()
+		assert.NoError(t, err)
+		assert.Len(t, configuration.Backends, 2)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("reload blocked by the startup delay")
+	}
+	assert.Len(t, configurationChan, 0)
+}
+
+func TestProvideStartupDelayStopped(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{Directory: tempDir, ProviderName: "file", StartupDelay: flaeg.Duration(200 * time.Millisecond)}
+
+	pool := safe.NewPool(context.Background())
+	require.NoError(t, pvd.Provide(configurationChan, pool, nil))
+	// Stop waits for the goroutine waiting for the startup delay, which returns without loading the configuration
+	pool.Stop()
+	assert.Len(t, configurationChan, 0)
+}
@@ -0,0 +1,63 @@
+package file
+
+import (
+	"github.com/containous/traefik/types"
+)
+
+// staticConfigurationOrigin is the origin of the frontends and backends of the StaticConfiguration.
+const staticConfigurationOrigin = "static configuration"
+
+// readsStaticOnly returns true if the StaticConfiguration is the only configuration, without any file to load.
+func (p *Provider) readsStaticOnly() bool {
+	return p.StaticConfiguration != nil && p.readsSingleFile() && p.resolved().filename == ""
+}
+
+// withStaticConfiguration merges the configuration loaded over the StaticConfiguration, following the merge strategy.
+// The StaticConfiguration is not modified.
+func (p *Provider) withStaticConfiguration(configuration *types.Configuration) (*types.Configuration, error) {
+	if p.StaticConfiguration == nil {
+		return configuration, nil
+	}
+
+	strategy, err := p.mergeStrategy()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &types.Configuration{
+		Frontends: make(map[string]*types.Frontend, len(p.StaticConfiguration.Frontends)+len(configuration.Frontends)),
+		Backends:  make(map[string]*types.Backend, len(p.StaticConfiguration.Backends)+len(configuration.Backends)),
+	}
+	for frontendName, frontend := range p.StaticConfiguration.Frontends {
+		merged.Frontends[frontendName] = frontend
+	}
+	for backendName, backend := range p.StaticConfiguration.Backends {
+		merged.Backends[backendName] = backend
+	}
+	origins := newConfigurationOrigins(staticConfigurationOrigin, merged)
+	warns := p.newWarnings()
+
+	loadedOrigins, ok := p.origins.Get().(*configurationOrigins)
+	if !ok {
+		loadedOrigins = newConfigurationOrigins(p.configurationSource(), configuration)
+	}
+	for backendName, backend := range configuration.Backends {
+		if err := mergeBackend(strategy, merged.Backends, origins.backends, backendName, loadedOrigins.backends[backendName], backend, warns); err != nil {
+			return nil, err
+		}
+	}
+	for frontendName, frontend := range configuration.Frontends {
+		mergeFrontend(strategy, merged.Frontends, origins.frontends, frontendName, loadedOrigins.frontends[frontendName], frontend, warns)
+	}
+
+	tlsSources := make(map[string]string)
+	merged.TLSConfiguration = mergeTLSConfigurations(nil, tlsSources, staticConfigurationOrigin, p.StaticConfiguration.TLSConfiguration, warns)
+	merged.TLSConfiguration = mergeTLSConfigurations(merged.TLSConfiguration, tlsSources, p.configurationSource(), configuration.TLSConfiguration, warns)
+
+	if err := warns.err(); err != nil {
+		return nil, err
+	}
+
+	p.setOrigins(origins)
+	return merged, nil
+}
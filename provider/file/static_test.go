@@ -0,0 +1,69 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticConfiguration() *types.Configuration {
+	return &types.Configuration{
+		Frontends: map[string]*types.Frontend{
+			"frontend1": {Backend: "backend1"},
+		},
+		Backends: map[string]*types.Backend{
+			"backend1": {Servers: map[string]types.Server{"server1": {URL: "http://10.0.0.1:80"}}},
+			"backend2": {Servers: map[string]types.Server{"server1": {URL: "http://10.0.0.2:80"}}},
+		},
+	}
+}
+
+func TestProvideStaticConfiguration(t *testing.T) {
+	configurationChan := make(chan types.ConfigMessage, 10)
+	pvd := &Provider{ProviderName: "file", StaticConfiguration: staticConfiguration()}
+	pvd.Watch = true
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	err := pvd.Provide(configurationChan, pool, nil)
+	require.NoError(t, err)
+
+	require.Len(t, configurationChan, 1)
+	msg := <-configurationChan
+	assert.Equal(t, staticConfiguration(), msg.Configuration)
+	assert.Equal(t, staticConfigurationOrigin, pvd.BackendFile("backend1"))
+}
+
+func TestBuildConfigurationStaticConfiguration(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "backends.toml",
+		backendWithURL("backend2", "http://172.17.0.2:80"),
+		backendWithURL("backend3", "http://172.17.0.3:80"))
+
+	static := staticConfiguration()
+	pvd := &Provider{Directory: tempDir, MergeStrategy: mergeStrategyReplace, StaticConfiguration: static}
+
+	configuration, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	require.Len(t, configuration.Backends, 3)
+	assert.Equal(t, "http://10.0.0.1:80", configuration.Backends["backend1"].Servers["server1"].URL)
+	assert.Equal(t, "http://172.17.0.2:80", configuration.Backends["backend2"].Servers["server1"].URL)
+	assert.Equal(t, "http://172.17.0.3:80", configuration.Backends["backend3"].Servers["server1"].URL)
+	assert.Contains(t, configuration.Frontends, "frontend1")
+
+	assert.Equal(t, staticConfigurationOrigin, pvd.BackendFile("backend1"))
+	assert.Equal(t, filepath.Join(tempDir, "backends.toml"), pvd.BackendFile("backend2"))
+
+	// The static configuration is left untouched
+	assert.Equal(t, staticConfiguration(), static)
+}
@@ -0,0 +1,48 @@
+package file
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/containous/traefik/types"
+)
+
+// loadStats describes what a configuration was loaded from.
+type loadStats struct {
+	// files holds the files loaded, including the included ones, sorted.
+	files       []string
+	directories int
+	// lastModified is the newest modification time of the files.
+	lastModified time.Time
+}
+
+// LoadedFiles returns the configuration files of the last configuration loaded, along with the files they include, sorted.
+// The standard input is returned as "-". A configuration loaded from a RemoteURL has no file.
+func (p *Provider) LoadedFiles() []string {
+	stats, ok := p.loadStats.Get().(*loadStats)
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), stats.files...)
+}
+
+// loadSummary describes the configuration loaded from the source.
+func loadSummary(configuration *types.Configuration, source string, stats *loadStats) string {
+	summary := fmt.Sprintf("Loaded %d frontends, %d backends and %d TLS configurations from %s",
+		len(configuration.Frontends), len(configuration.Backends), len(configuration.TLSConfiguration), source)
+
+	var details string
+	switch {
+	case stats.directories > 0:
+		details = fmt.Sprintf("%d files in %d directories", len(stats.files), stats.directories)
+	case len(stats.files) > 0:
+		details = fmt.Sprintf("%d files", len(stats.files))
+	}
+	if !stats.lastModified.IsZero() {
+		details += ", last modified at " + stats.lastModified.Format(time.RFC3339)
+	}
+	if details != "" {
+		summary += " (" + details + ")"
+	}
+	return summary
+}
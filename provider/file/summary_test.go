@@ -0,0 +1,136 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/provider"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSummary(t *testing.T) {
+	configuration := &types.Configuration{
+		Frontends: map[string]*types.Frontend{"frontend1": {}, "frontend2": {}},
+		Backends:  map[string]*types.Backend{"backend1": {}},
+	}
+
+	testCases := []struct {
+		desc     string
+		source   string
+		stats    *loadStats
+		expected string
+	}{
+		{
+			desc:     "directory",
+			source:   `directory "/etc/traefik"`,
+			stats:    &loadStats{files: []string{"a.toml", "b.toml", "sub/c.toml"}, directories: 2},
+			expected: `Loaded 2 frontends, 1 backends and 0 TLS configurations from directory "/etc/traefik" (3 files in 2 directories)`,
+		},
+		{
+			desc:     "directory with modification time",
+			source:   `directory "/etc/traefik"`,
+			stats:    &loadStats{files: []string{"a.toml", "b.toml", "sub/c.toml"}, directories: 2, lastModified: time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)},
+			expected: `Loaded 2 frontends, 1 backends and 0 TLS configurations from directory "/etc/traefik" (3 files in 2 directories, last modified at 2018-03-01T10:00:00Z)`,
+		},
+		{
+			desc:     "file",
+			source:   `file "rules.toml"`,
+			stats:    &loadStats{files: []string{"a.toml", "b.toml"}},
+			expected: `Loaded 2 frontends, 1 backends and 0 TLS configurations from file "rules.toml" (2 files)`,
+		},
+		{
+			desc:     "URL",
+			source:   `URL "https://config.example.com/traefik.toml"`,
+			stats:    &loadStats{},
+			expected: `Loaded 2 frontends, 1 backends and 0 TLS configurations from URL "https://config.example.com/traefik.toml"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, loadSummary(configuration, test.source, test.stats))
+		})
+	}
+}
+
+func TestBuildConfigurationLoadStats(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+	includeDir := createTempDir(t, "testinclude")
+	defer os.RemoveAll(includeDir)
+
+	subDir := createSubDir(t, tempDir, "sub")
+	createFile(t, includeDir, "common.toml", backendWithURL("backend3", "http://172.17.0.1:80"))
+	createFile(t, tempDir, "a.toml",
+		fmt.Sprintf("[include]\nfiles = [%q]\n", filepath.Join(includeDir, "common.toml")),
+		backendWithURL("backend1", "http://172.17.0.1:80"))
+	createFile(t, subDir, "b.toml", backendWithURL("backend2", "http://172.17.0.1:80"))
+
+	// The included file is the newest one of the file, b.toml the newest one of the directory
+	fileModTime := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+	directoryModTime := fileModTime.Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(tempDir, "a.toml"), fileModTime, fileModTime.Add(-time.Hour)))
+	require.NoError(t, os.Chtimes(filepath.Join(includeDir, "common.toml"), fileModTime, fileModTime))
+	require.NoError(t, os.Chtimes(filepath.Join(subDir, "b.toml"), directoryModTime, directoryModTime))
+
+	pvd := &Provider{Directory: tempDir}
+	_, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	stats := pvd.loadStats.Get().(*loadStats)
+	assert.Len(t, stats.files, 3)
+	assert.Equal(t, 2, stats.directories)
+	assert.True(t, directoryModTime.Equal(stats.lastModified), "last modified at %s", stats.lastModified)
+
+	pvd = &Provider{Filename: filepath.Join(tempDir, "a.toml")}
+	_, err = pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	stats = pvd.loadStats.Get().(*loadStats)
+	assert.Len(t, stats.files, 2)
+	assert.Equal(t, 0, stats.directories)
+	assert.True(t, fileModTime.Equal(stats.lastModified), "last modified at %s", stats.lastModified)
+}
+
+func TestLoadedFiles(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, ignoreFilename, "*.draft.toml\n")
+	createFile(t, tempDir, "draft.draft.toml", createBackendConfiguration(1))
+	createFile(t, tempDir, ".hidden.toml", createBackendConfiguration(1))
+	createFile(t, tempDir, "notes.txt", "")
+	backends := createFile(t, tempDir, "backends.toml", `
+[include]
+files = ["shared/frontends.toml"]
+`+createBackendConfiguration(1))
+	sharedDir := createSubDir(t, tempDir, "shared")
+	frontends := createFile(t, sharedDir, "frontends.toml", createFrontendConfiguration(1))
+	subDir := createSubDir(t, tempDir, "sub")
+	other := createFile(t, subDir, "other.yml", "")
+
+	pvd := &Provider{Directory: tempDir}
+	assert.Empty(t, pvd.LoadedFiles())
+
+	_, err := pvd.BuildConfiguration()
+	require.NoError(t, err)
+
+	// The included file is also loaded as a file of the directory
+	expected := []string{backends.Name(), frontends.Name(), other.Name()}
+	sort.Strings(expected)
+	assert.Equal(t, expected, pvd.LoadedFiles())
+
+	pvd = &Provider{BaseProvider: provider.BaseProvider{Filename: backends.Name()}}
+	_, err = pvd.BuildConfiguration()
+	require.NoError(t, err)
+	assert.Equal(t, []string{backends.Name(), frontends.Name()}, pvd.LoadedFiles())
+}
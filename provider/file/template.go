@@ -0,0 +1,411 @@
+package file
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/containous/traefik/log"
+)
+
+// lookupSRV resolves SRV records, replaced in tests.
+var lookupSRV = net.LookupSRV
+
+// templateErrorLocation matches the location prefix of the text/template errors: "template: name:line:column: ".
+var templateErrorLocation = regexp.MustCompile(`^template: [^:]*:(\d+):(?:(\d+):)? `)
+
+// tomlErrorLine matches the line reported by the TOML decoding errors: "Near line 3 (last key parsed ...)".
+var tomlErrorLine = regexp.MustCompile(`Near line (\d+)`)
+
+// templateExtension is the extension of the files rendered as templates before being decoded.
+const templateExtension = ".tmpl"
+
+func isTemplateFile(filename string) bool {
+	return strings.HasSuffix(uncompressedName(filename), templateExtension)
+}
+
+// partialTemplatePrefix starts the name of the templates only meant to be included by other templates,
+// such as _backend.tmpl, which are not loaded from the directories and archives.
+const partialTemplatePrefix = "_"
+
+// isPartialTemplate returns true if the file is a template whose name starts with the partialTemplatePrefix.
+func isPartialTemplate(filename string) bool {
+	return isTemplateFile(filename) && strings.HasPrefix(filepath.Base(filename), partialTemplatePrefix)
+}
+
+// loadTemplateValues reads the TemplateValuesFile, whose values are the data rendered by the templates.
+func (p *Provider) loadTemplateValues() error {
+	if p.TemplateValuesFile == "" {
+		p.templateValues.Set(nil)
+		return nil
+	}
+
+	content, err := readFile(p.TemplateValuesFile)
+	if err != nil {
+		return fmt.Errorf("error reading template values file %s: %v", p.TemplateValuesFile, err)
+	}
+
+	// Files without a known extension are read as TOML, like the configuration files
+	f, ok := formatFromFilename(p.TemplateValuesFile)
+	if !ok {
+		f = formatTOML
+	}
+
+	values, err := decodeValues(content, f)
+	if err != nil {
+		return fmt.Errorf("error reading template values file %s: %v", p.TemplateValuesFile, err)
+	}
+
+	p.templateValues.Set(values)
+	return nil
+}
+
+// isTemplateValuesFile returns true if the path is the TemplateValuesFile.
+func (p *Provider) isTemplateValuesFile(name string) bool {
+	return p.TemplateValuesFile != "" && filepath.Clean(name) == filepath.Clean(p.TemplateValuesFile)
+}
+
+// templateFuncMap returns the functions available in the template filename,
+// the includeStack holding the templates including it.
+// Relative paths given to the functions are relative to the directory of the template.
+// The paths of the files read by the functions, such as the included templates, are added to readFiles.
+func templateFuncMap(filename string, includeStack []string, readFiles map[string]struct{}) template.FuncMap {
+	// The files looked up are decoded once per rendering
+	lookupFiles := make(map[string]map[string]interface{})
+
+	read := func(name string) {
+		readFiles[filepath.Clean(resolvePath(filename, name))] = struct{}{}
+	}
+
+	return template.FuncMap{
+		"env":   os.Getenv,
+		"envOr": envOr,
+		"readFile": func(name string) (string, error) {
+			read(name)
+			return readTemplateFile(filename, name)
+		},
+		"glob": func(pattern string) ([]string, error) {
+			return globTemplateFiles(filename, pattern)
+		},
+		"base64encode": base64Encode,
+		"base64decode": func(value string) (string, error) {
+			return base64Decode(filename, value)
+		},
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"trim":    strings.TrimSpace,
+		"replace": replaceAll,
+		"split":   strings.Split,
+		"dnsSRV": func(name string) ([]string, error) {
+			return resolveSRV(filename, name)
+		},
+		"include": func(name string, data interface{}) (string, error) {
+			rendered, included, err := includeTemplate(filename, includeStack, name, data)
+			for _, file := range included {
+				readFiles[file] = struct{}{}
+			}
+			return rendered, err
+		},
+		"sha256":    sha256Hex,
+		"md5":       md5Hex,
+		"shortHash": shortHash,
+		"quote":     quote,
+		"csv": func(name string) ([]map[string]string, error) {
+			read(name)
+			return readTemplateCSV(filename, name)
+		},
+		"lookup": func(name, key string) (interface{}, error) {
+			read(name)
+			return lookupTemplateValue(filename, lookupFiles, name, key)
+		},
+	}
+}
+
+// dumpRenderedTemplate writes the text rendered from the template filename to a temporary file,
+// and returns the error decoding it along with the path of this file.
+func dumpRenderedTemplate(filename string, rendered string, decodeErr error) error {
+	dump, err := ioutil.TempFile("", "traefik-"+filepath.Base(uncompressedName(filename))+"-")
+	if err != nil {
+		log.Warnf("Unable to write the rendered template %s: %v", filename, err)
+		return decodeErr
+	}
+	defer dump.Close()
+
+	if _, err := dump.WriteString(rendered); err != nil {
+		log.Warnf("Unable to write the rendered template %s: %v", filename, err)
+		return decodeErr
+	}
+	return fmt.Errorf("%v (rendered template written to %s)", decodeErr, dump.Name())
+}
+
+// renderTemplate renders the template content read from filename,
+// and returns the sorted paths of the files read to render it, such as the templates it includes or the files it looks up.
+func renderTemplate(filename string, content string, templateObjects interface{}) (string, []string, error) {
+	return renderIncludedTemplate(filename, content, templateObjects, nil)
+}
+
+// renderIncludedTemplate renders the template content read from filename, included by the templates of the includeStack,
+// and returns the sorted paths of the files read to render it.
+func renderIncludedTemplate(filename string, content string, templateObjects interface{}, includeStack []string) (string, []string, error) {
+	readFiles := make(map[string]struct{})
+	tmpl, err := template.New(filepath.Base(filename)).Funcs(templateFuncMap(filename, includeStack, readFiles)).Parse(content)
+	if err != nil {
+		return "", nil, templateError("unable to parse template", err)
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, templateObjects); err != nil {
+		return "", nil, templateError("unable to render template", err)
+	}
+
+	return buffer.String(), sortedFiles(readFiles), nil
+}
+
+// templateError rewrites the location reported by text/template as a line and a column of the template.
+func templateError(context string, err error) error {
+	message := err.Error()
+
+	match := templateErrorLocation.FindStringSubmatch(message)
+	if match == nil {
+		return fmt.Errorf("%s: %s", context, message)
+	}
+
+	location := "line " + match[1]
+	if match[2] != "" {
+		location += ", column " + match[2]
+	}
+	return fmt.Errorf("%s at %s: %s", context, location, message[len(match[0]):])
+}
+
+// envOr returns the value of the environment variable key, or fallback if it is not set.
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// globTemplateFiles returns the sorted paths of the files matching the pattern referenced by the template.
+func globTemplateFiles(templateFile, pattern string) ([]string, error) {
+	paths, err := filepath.Glob(resolvePath(templateFile, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s in template %s: %v", pattern, templateFile, err)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// replaceAll returns a copy of s with all the occurrences of old replaced by new.
+func replaceAll(s, old, new string) string {
+	return strings.Replace(s, old, new, -1)
+}
+
+func base64Encode(value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+// base64Decode returns the value decoded from standard base64 by the template.
+func base64Decode(templateFile, value string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("unable to decode base64 value in template %s: %v", templateFile, err)
+	}
+	return string(decoded), nil
+}
+
+// quote returns the value as a double-quoted string with its special characters escaped,
+// which is a valid string in TOML, YAML and JSON, such as inline PEM certificates spanning several lines.
+func quote(value string) (string, error) {
+	var quoted bytes.Buffer
+	encoder := json.NewEncoder(&quoted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	// DEL is valid in a JSON string, but is a control character which must be escaped in TOML
+	return strings.Replace(strings.TrimSuffix(quoted.String(), "\n"), "\x7f", `\u007f`, -1), nil
+}
+
+// renderedLineHint adds to the error decoding a rendered TOML template the rendered line it reports,
+// where a value interpolated in a string without being escaped, containing a quote or a newline, usually ends the string early.
+func renderedLineHint(rendered string, f format, decodeErr error) error {
+	if f != formatTOML {
+		return decodeErr
+	}
+	match := tomlErrorLine.FindStringSubmatch(decodeErr.Error())
+	if match == nil {
+		return decodeErr
+	}
+
+	lines := strings.Split(rendered, "\n")
+	line, err := strconv.Atoi(match[1])
+	if err != nil || line < 1 || line > len(lines) {
+		return decodeErr
+	}
+	return fmt.Errorf("%v, rendered line %d: %s (escape the values interpolated in strings with quote, such as {{ quote .value }})",
+		decodeErr, line, strings.TrimSpace(lines[line-1]))
+}
+
+// sha256Hex returns the hexadecimal SHA-256 hash of the value.
+func sha256Hex(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+// md5Hex returns the hexadecimal MD5 hash of the value.
+func md5Hex(value string) string {
+	hash := md5.Sum([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+// shortHash returns the first length hexadecimal characters of the SHA-256 hash of the value.
+func shortHash(length int, value string) (string, error) {
+	hash := sha256Hex(value)
+	if length <= 0 || length > len(hash) {
+		return "", fmt.Errorf("invalid short hash length %d, expected between 1 and %d", length, len(hash))
+	}
+	return hash[:length], nil
+}
+
+// resolveSRV returns the host:port addresses of the SRV records of the name resolved by the template,
+// in the order of the records priority and weight.
+func resolveSRV(templateFile, name string) ([]string, error) {
+	_, records, err := lookupSRV("", "", name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve SRV records %s in template %s: %v", name, templateFile, err)
+	}
+
+	addresses := make([]string, 0, len(records))
+	for _, record := range records {
+		addresses = append(addresses, net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))))
+	}
+	return addresses, nil
+}
+
+// readTemplateFile returns the content of the file name referenced by the template.
+func readTemplateFile(templateFile, name string) (string, error) {
+	path := resolvePath(templateFile, name)
+	content, err := readFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read file %s referenced by template %s: %v", path, templateFile, err)
+	}
+	return string(content), nil
+}
+
+// readTemplateCSV returns the rows of the CSV file name referenced by the template, as maps keyed by the fields of the header.
+func readTemplateCSV(templateFile, name string) ([]map[string]string, error) {
+	path := resolvePath(templateFile, name)
+	content, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file %s referenced by template %s: %v", path, templateFile, err)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file %s referenced by template %s: %v", path, templateFile, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i, field := range header {
+		if field == "" {
+			return nil, fmt.Errorf("invalid CSV file %s referenced by template %s: empty header field %d", path, templateFile, i+1)
+		}
+		for _, previous := range header[:i] {
+			if previous == field {
+				return nil, fmt.Errorf("invalid CSV file %s referenced by template %s: duplicate header field %q", path, templateFile, field)
+			}
+		}
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, field := range header {
+			row[field] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// lookupTemplateValue returns the value at the dotted key path, such as network.subnet, of the TOML, YAML or JSON file name
+// referenced by the template. The decoded files are kept in lookupFiles, keyed by path.
+func lookupTemplateValue(templateFile string, lookupFiles map[string]map[string]interface{}, name, key string) (interface{}, error) {
+	path := resolvePath(templateFile, name)
+
+	values, ok := lookupFiles[path]
+	if !ok {
+		content, err := readFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read file %s referenced by template %s: %v", path, templateFile, err)
+		}
+
+		// Files without a known extension are read as TOML, like the configuration files
+		f, ok := formatFromFilename(path)
+		if !ok {
+			f = formatTOML
+		}
+
+		values, err = decodeValues(content, f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file %s referenced by template %s: %v", path, templateFile, err)
+		}
+		lookupFiles[path] = values
+	}
+
+	var value interface{} = values
+	elements := strings.Split(key, ".")
+	for i, element := range elements {
+		table, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("key %q not found in file %s referenced by template %s: %s is not a table", key, path, templateFile, strings.Join(elements[:i], "."))
+		}
+		if value, ok = table[element]; !ok {
+			return nil, fmt.Errorf("key %q not found in file %s referenced by template %s", key, path, templateFile)
+		}
+	}
+	return value, nil
+}
+
+// includeTemplate renders the template file name included by the template with the data,
+// and returns the paths of the included template and of the files read to render it, such as the templates it includes in turn.
+func includeTemplate(templateFile string, includeStack []string, name string, data interface{}) (string, []string, error) {
+	path := resolvePath(templateFile, name)
+
+	includeStack = append(includeStack, templateFile)
+	for _, including := range includeStack {
+		if filepath.Clean(including) == filepath.Clean(path) {
+			return "", nil, fmt.Errorf("template include cycle detected: %s -> %s", strings.Join(includeStack, " -> "), path)
+		}
+	}
+
+	content, err := readFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read template %s included by template %s: %v", path, templateFile, err)
+	}
+
+	rendered, readFiles, err := renderIncludedTemplate(path, string(content), data, includeStack)
+	if err != nil {
+		return "", nil, fmt.Errorf("template %s included by template %s: %v", path, templateFile, err)
+	}
+	return rendered, append([]string{filepath.Clean(path)}, readFiles...), nil
+}
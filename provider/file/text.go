@@ -0,0 +1,54 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/containous/traefik/log"
+)
+
+// notTextFileError is returned for the configuration files whose content is not text,
+// such as binary files with a configuration extension, which the decoders would report with confusing errors.
+type notTextFileError struct {
+	filename string
+	reason   string
+}
+
+func (e *notTextFileError) Error() string {
+	return fmt.Sprintf("error reading configuration file %s: not a text config file, %s", e.filename, e.reason)
+}
+
+// checkTextContent returns a notTextFileError if the content, decompressed and decrypted, is not UTF-8 text:
+// if it contains a NUL byte or an invalid UTF-8 sequence.
+func checkTextContent(filename string, content []byte) error {
+	if offset := bytes.IndexByte(content, 0); offset >= 0 {
+		return &notTextFileError{filename: filename, reason: fmt.Sprintf("it contains a NUL byte at offset %d", offset)}
+	}
+	if !utf8.Valid(content) {
+		return &notTextFileError{filename: filename, reason: fmt.Sprintf("it contains invalid UTF-8 at offset %d", invalidUTF8Offset(content))}
+	}
+	return nil
+}
+
+// invalidUTF8Offset returns the offset of the first invalid UTF-8 sequence of the content, or -1 if it is valid.
+func invalidUTF8Offset(content []byte) int {
+	for offset := 0; offset < len(content); {
+		r, size := utf8.DecodeRune(content[offset:])
+		if r == utf8.RuneError && size == 1 {
+			return offset
+		}
+		offset += size
+	}
+	return -1
+}
+
+// logSkippedFile logs the configuration file skipped because it can not be loaded,
+// as a warning if it is not even a text file.
+func logSkippedFile(err error) {
+	if _, ok := err.(*notTextFileError); ok {
+		log.Warnf("Skipping configuration file which is not text: %v", err)
+		return
+	}
+	log.Errorf("Skipping invalid configuration file: %v", err)
+}
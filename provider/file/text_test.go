@@ -0,0 +1,113 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTextContent(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		content       []byte
+		expectedError string
+	}{
+		{
+			desc:    "empty",
+			content: []byte{},
+		},
+		{
+			desc:    "UTF-8 text",
+			content: []byte("[backends.backend1.servers.server1]\nurl = \"http://10.0.0.1:80\" # Træfik\n"),
+		},
+		{
+			desc:    "UTF-8 byte order mark",
+			content: []byte("\xef\xbb\xbf[backends]\n"),
+		},
+		{
+			desc:          "NUL byte",
+			content:       []byte("[backends]\x00\x01\x02"),
+			expectedError: "not a text config file, it contains a NUL byte at offset 10",
+		},
+		{
+			desc:          "UTF-16",
+			content:       []byte("\xff\xfe[\x00b\x00"),
+			expectedError: "not a text config file, it contains a NUL byte at offset 3",
+		},
+		{
+			desc:          "invalid UTF-8",
+			content:       []byte("[backends]\n\xc3\x28"),
+			expectedError: "not a text config file, it contains invalid UTF-8 at offset 11",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkTextContent("rules.toml", test.content)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.IsType(t, &notTextFileError{}, err)
+			assert.Equal(t, "error reading configuration file rules.toml: "+test.expectedError, err.Error())
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryBinaryFile(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		skipInvalidFiles bool
+	}{
+		{
+			desc: "binary file rejected",
+		},
+		{
+			desc:             "binary file skipped",
+			skipInvalidFiles: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			createFile(t, tempDir, "backends.toml", createBackendConfiguration(2))
+			binaryFile := createFile(t, tempDir, "image.toml", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+			pvd := &Provider{SkipInvalidFiles: test.skipInvalidFiles}
+			configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+			if !test.skipInvalidFiles {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), binaryFile.Name())
+				assert.Contains(t, err.Error(), "not a text config file")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, configuration.Backends, 2)
+		})
+	}
+}
+
+func TestLoadFileConfigBinaryFile(t *testing.T) {
+	tempDir := createTempDir(t, "testfile")
+	defer os.RemoveAll(tempDir)
+
+	binaryFile := createFile(t, tempDir, "rules.toml", "\xff\xd8\xff\xe0")
+
+	_, err := (&Provider{}).loadFileConfig(binaryFile.Name())
+	require.Error(t, err)
+	assert.Equal(t, "error reading configuration file "+binaryFile.Name()+": not a text config file, it contains invalid UTF-8 at offset 0", err.Error())
+}
@@ -0,0 +1,148 @@
+package file
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+)
+
+// CheckConfiguration loads the configuration and returns an error describing the first problem preventing its loading,
+// or all the inconsistencies found in it, whatever StrictValidation is.
+// It does not require the provider to be started.
+func (p *Provider) CheckConfiguration() error {
+	if err := p.resolvePaths(); err != nil {
+		return err
+	}
+	configuration, err := p.loadConfiguration()
+	if err != nil {
+		return err
+	}
+
+	if !p.AllowEmptyConfiguration && isEmptyConfiguration(configuration) {
+		return fmt.Errorf("empty configuration loaded from %s", p.configurationSource())
+	}
+
+	if problems := p.problems(configuration); len(problems) > 0 {
+		return invalidConfigurationError(problems)
+	}
+	return nil
+}
+
+// validateConfiguration checks the consistency of the configuration.
+// The problems found are logged, and returned as an error if StrictValidation is enabled.
+func (p *Provider) validateConfiguration(configuration *types.Configuration) error {
+	problems := p.problems(configuration)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if p.StrictValidation {
+		return invalidConfigurationError(problems)
+	}
+
+	for _, problem := range problems {
+		log.Warnf("Invalid configuration: %s", problem)
+	}
+	return nil
+}
+
+// problems returns the inconsistencies of the configuration, along with its invalid rules with ValidateRules, sorted.
+func (p *Provider) problems(configuration *types.Configuration) []string {
+	problems := configurationProblems(configuration)
+	if !p.ValidateRules {
+		return problems
+	}
+
+	problems = append(problems, p.ruleProblems(configuration)...)
+	sort.Strings(problems)
+	return problems
+}
+
+// ruleProblems returns the routing rules of the frontends which the RuleValidator rejects.
+func (p *Provider) ruleProblems(configuration *types.Configuration) []string {
+	if p.RuleValidator == nil {
+		log.Warn("Unable to validate the routing rules of the frontends outside of Træfik, skipping")
+		return nil
+	}
+
+	var problems []string
+	for frontendName, frontend := range configuration.Frontends {
+		for routeName, route := range frontend.Routes {
+			if err := p.RuleValidator(route.Rule); err != nil {
+				problems = append(problems, fmt.Sprintf("route %s of frontend %s has an invalid rule %q: %v", routeName, frontendName, route.Rule, err))
+			}
+		}
+	}
+	return problems
+}
+
+// configurationProblems returns the inconsistencies of the configuration, sorted.
+func configurationProblems(configuration *types.Configuration) []string {
+	var problems []string
+
+	for frontendName, frontend := range configuration.Frontends {
+		if _, exists := configuration.Backends[frontend.Backend]; !exists {
+			problems = append(problems, fmt.Sprintf("frontend %s references an undefined backend %q", frontendName, frontend.Backend))
+		}
+	}
+
+	for backendName, backend := range configuration.Backends {
+		problems = append(problems, serverWeightProblems(backendName, backend)...)
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// serverWeightProblems returns the servers of the backend which the weighted round robin load balancer rejects:
+// the ones with a negative weight. A zero weight is not a problem, since it stands for the default weight.
+func serverWeightProblems(backendName string, backend *types.Backend) []string {
+	if method, err := types.NewLoadBalancerMethod(backend.LoadBalancer); err == nil && method != types.Wrr {
+		return nil
+	}
+
+	var problems []string
+	for serverName, server := range backend.Servers {
+		if server.Weight < 0 {
+			problems = append(problems, fmt.Sprintf("server %s of backend %s has a negative weight %d", serverName, backendName, server.Weight))
+		}
+	}
+	if len(problems) > 0 && len(problems) == len(backend.Servers) {
+		problems = append(problems, fmt.Sprintf("backend %s has no server with a valid weight", backendName))
+	}
+	return problems
+}
+
+// warnDuplicateServers raises a warning for each URL used by several servers of a backend of the file,
+// which usually is a copy-paste mistake.
+func warnDuplicateServers(filename string, configuration *types.Configuration, warns *warnings) {
+	for backendName, backend := range configuration.Backends {
+		for _, url := range duplicateServerURLs(backend) {
+			warns.add("Backend %s of %s has several servers with the URL %s", backendName, filename, url)
+		}
+	}
+}
+
+// duplicateServerURLs returns the URLs used by several servers of the backend, sorted.
+func duplicateServerURLs(backend *types.Backend) []string {
+	servers := make(map[string]int, len(backend.Servers))
+	for _, server := range backend.Servers {
+		servers[server.URL]++
+	}
+
+	var duplicates []string
+	for url, count := range servers {
+		if count > 1 {
+			duplicates = append(duplicates, url)
+		}
+	}
+	sort.Strings(duplicates)
+	return duplicates
+}
+
+func invalidConfigurationError(problems []string) error {
+	return fmt.Errorf("invalid configuration: %s", strings.Join(problems, ", "))
+}
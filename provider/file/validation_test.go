@@ -0,0 +1,297 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		strictValidation bool
+		configuration    *types.Configuration
+		expectedError    string
+	}{
+		{
+			desc: "valid configuration",
+			configuration: &types.Configuration{
+				Frontends: map[string]*types.Frontend{"frontend1": {Backend: "backend1"}},
+				Backends:  map[string]*types.Backend{"backend1": {}},
+			},
+		},
+		{
+			desc: "undefined backend",
+			configuration: &types.Configuration{
+				Frontends: map[string]*types.Frontend{"frontend1": {Backend: "backend2"}},
+				Backends:  map[string]*types.Backend{"backend1": {}},
+			},
+		},
+		{
+			desc:             "undefined backend with strict validation",
+			strictValidation: true,
+			configuration: &types.Configuration{
+				Frontends: map[string]*types.Frontend{"frontend1": {Backend: "backend2"}},
+				Backends:  map[string]*types.Backend{"backend1": {}},
+			},
+			expectedError: `invalid configuration: frontend frontend1 references an undefined backend "backend2"`,
+		},
+		{
+			desc:             "negative weights with strict validation",
+			strictValidation: true,
+			configuration: &types.Configuration{
+				Backends: map[string]*types.Backend{
+					"backend1": {
+						LoadBalancer: &types.LoadBalancer{Method: "wrr"},
+						Servers:      map[string]types.Server{"server1": {URL: "http://172.17.0.1:80", Weight: -1}},
+					},
+				},
+			},
+			expectedError: "invalid configuration: backend backend1 has no server with a valid weight, server server1 of backend backend1 has a negative weight -1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pvd := &Provider{StrictValidation: test.strictValidation}
+			err := pvd.validateConfiguration(test.configuration)
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.expectedError, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		directory     bool
+		content       string
+		expectedError string
+	}{
+		{
+			desc:    "valid file",
+			content: "[frontends.frontend1]\nbackend = \"backend1\"\n[backends.backend1.servers.server1]\nurl = \"http://172.17.0.1:80\"\n",
+		},
+		{
+			desc:          "file with undefined backend",
+			content:       "[frontends.frontend1]\nbackend = \"backend2\"\n[backends.backend1.servers.server1]\nurl = \"http://172.17.0.1:80\"\n",
+			expectedError: `invalid configuration: frontend frontend1 references an undefined backend "backend2"`,
+		},
+		{
+			desc:          "invalid file",
+			content:       "[frontends",
+			expectedError: "error reading configuration file",
+		},
+		{
+			desc:      "valid directory",
+			directory: true,
+			content:   "[frontends.frontend1]\nbackend = \"backend1\"\n[backends.backend1.servers.server1]\nurl = \"http://172.17.0.1:80\"\n",
+		},
+		{
+			desc:          "directory with undefined backend",
+			directory:     true,
+			content:       "[frontends.frontend1]\nbackend = \"backend2\"\n",
+			expectedError: `invalid configuration: frontend frontend1 references an undefined backend "backend2"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testcheck")
+			defer os.RemoveAll(tempDir)
+
+			tempFile := createFile(t, tempDir, "rules.toml", test.content)
+
+			pvd := &Provider{}
+			if test.directory {
+				pvd.Directory = tempDir
+			} else {
+				pvd.Filename = tempFile.Name()
+			}
+
+			err := pvd.CheckConfiguration()
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDuplicateServerURLs(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		servers  map[string]types.Server
+		expected []string
+	}{
+		{
+			desc: "distinct URLs",
+			servers: map[string]types.Server{
+				"server1": {URL: "http://172.17.0.1:80"},
+				"server2": {URL: "http://172.17.0.2:80"},
+			},
+		},
+		{
+			desc: "duplicate URLs",
+			servers: map[string]types.Server{
+				"server1": {URL: "http://172.17.0.2:80"},
+				"server2": {URL: "http://172.17.0.1:80"},
+				"server3": {URL: "http://172.17.0.2:80"},
+				"server4": {URL: "http://172.17.0.1:80"},
+				"server5": {URL: "http://172.17.0.3:80"},
+			},
+			expected: []string{"http://172.17.0.1:80", "http://172.17.0.2:80"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, duplicateServerURLs(&types.Backend{Servers: test.servers}))
+		})
+	}
+}
+
+func TestServerWeightProblems(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		backend  *types.Backend
+		expected []string
+	}{
+		{
+			desc: "weighted servers",
+			backend: &types.Backend{
+				LoadBalancer: &types.LoadBalancer{Method: "wrr"},
+				Servers: map[string]types.Server{
+					"server1": {URL: "http://172.17.0.1:80"},
+					"server2": {URL: "http://172.17.0.2:80", Weight: 2},
+				},
+			},
+		},
+		{
+			desc: "negative weight",
+			backend: &types.Backend{
+				LoadBalancer: &types.LoadBalancer{Method: "wrr"},
+				Servers: map[string]types.Server{
+					"server1": {URL: "http://172.17.0.1:80", Weight: -1},
+					"server2": {URL: "http://172.17.0.2:80", Weight: 1},
+				},
+			},
+			expected: []string{"server server1 of backend backend1 has a negative weight -1"},
+		},
+		{
+			desc: "negative weights only with the default method",
+			backend: &types.Backend{
+				Servers: map[string]types.Server{"server1": {URL: "http://172.17.0.1:80", Weight: -1}},
+			},
+			expected: []string{
+				"server server1 of backend backend1 has a negative weight -1",
+				"backend backend1 has no server with a valid weight",
+			},
+		},
+		{
+			desc: "negative weight with dynamic round robin",
+			backend: &types.Backend{
+				LoadBalancer: &types.LoadBalancer{Method: "drr"},
+				Servers:      map[string]types.Server{"server1": {URL: "http://172.17.0.1:80", Weight: -1}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, serverWeightProblems("backend1", test.backend))
+		})
+	}
+}
+
+func TestValidateConfigurationRules(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		validateRules    bool
+		strictValidation bool
+		noValidator      bool
+		expectedError    string
+	}{
+		{
+			desc:             "rules not validated",
+			strictValidation: true,
+		},
+		{
+			desc:          "invalid rule warned",
+			validateRules: true,
+		},
+		{
+			desc:             "invalid rule with strict validation",
+			validateRules:    true,
+			strictValidation: true,
+			expectedError:    `invalid configuration: route route1 of frontend frontend1 has an invalid rule "Hots:foo": invalid rule`,
+		},
+		{
+			desc:             "no rule validator",
+			validateRules:    true,
+			strictValidation: true,
+			noValidator:      true,
+		},
+	}
+
+	configuration := &types.Configuration{
+		Frontends: map[string]*types.Frontend{
+			"frontend1": {
+				Backend: "backend1",
+				Routes:  map[string]types.Route{"route1": {Rule: "Hots:foo"}},
+			},
+			"frontend2": {
+				Backend: "backend1",
+				Routes:  map[string]types.Route{"route1": {Rule: "Host:foo"}},
+			},
+		},
+		Backends: map[string]*types.Backend{"backend1": {}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pvd := &Provider{ValidateRules: test.validateRules, StrictValidation: test.strictValidation}
+			if !test.noValidator {
+				pvd.RuleValidator = func(rule string) error {
+					if rule != "Host:foo" {
+						return errors.New("invalid rule")
+					}
+					return nil
+				}
+			}
+
+			err := pvd.validateConfiguration(configuration)
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.expectedError, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
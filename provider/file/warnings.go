@@ -0,0 +1,43 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containous/traefik/log"
+)
+
+// warnings collects the warnings raised while loading a configuration, such as the definitions found in several files.
+// They are logged as they are raised, unless WarningsAsErrors makes them fail the loading, all of them being reported at once.
+type warnings struct {
+	asErrors bool
+	messages []string
+}
+
+func (p *Provider) newWarnings() *warnings {
+	return &warnings{asErrors: p.WarningsAsErrors}
+}
+
+// add logs the warning, or collects it if warnings are errors. A nil *warnings only logs it.
+func (w *warnings) add(format string, args ...interface{}) {
+	if w == nil || !w.asErrors {
+		log.Warnf(format, args...)
+		return
+	}
+	w.messages = append(w.messages, fmt.Sprintf(format, args...))
+}
+
+// merge collects the warnings of other, raised earlier by the loading of a file.
+func (w *warnings) merge(other *warnings) {
+	if other != nil {
+		w.messages = append(w.messages, other.messages...)
+	}
+}
+
+// err returns the warnings collected as an error, or nil if there are none.
+func (w *warnings) err() error {
+	if w == nil || len(w.messages) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d warnings treated as errors: %s", len(w.messages), strings.Join(w.messages, "; "))
+}
@@ -0,0 +1,80 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFileConfigFromDirectoryWarningsAsErrors(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		warningsAsErrors bool
+		expectedError    []string
+	}{
+		{
+			desc: "warnings logged",
+		},
+		{
+			desc:             "warnings as errors",
+			warningsAsErrors: true,
+			expectedError: []string{
+				"3 warnings treated as errors",
+				"Backend backend1 of ",
+				"Frontend frontend1 of ",
+				"has several servers with the URL http://172.17.0.2:80",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			createFile(t, tempDir, "a.toml", createBackendConfiguration(1)+createFrontendConfiguration(1))
+			createFile(t, tempDir, "b.toml", createBackendConfiguration(1)+createFrontendConfiguration(1))
+			createFile(t, tempDir, "c.toml", `
+[backends.backend2.servers.server1]
+url = "http://172.17.0.2:80"
+[backends.backend2.servers.server2]
+url = "http://172.17.0.2:80"
+`)
+
+			pvd := &Provider{WarningsAsErrors: test.warningsAsErrors}
+			configuration, err := pvd.loadFileConfigFromDirectory(tempDir)
+			if len(test.expectedError) > 0 {
+				require.Error(t, err)
+				for _, expected := range test.expectedError {
+					assert.Contains(t, err.Error(), expected)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, configuration.Backends, 2)
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	var logged *warnings
+	logged.add("logged %s", "only")
+	assert.NoError(t, logged.err())
+
+	warns := &warnings{asErrors: true}
+	assert.NoError(t, warns.err())
+
+	warns.add("first %d", 1)
+	other := &warnings{asErrors: true}
+	other.add("second %d", 2)
+	warns.merge(other)
+	warns.merge(nil)
+
+	assert.EqualError(t, warns.err(), "2 warnings treated as errors: first 1; second 2")
+}
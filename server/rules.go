@@ -278,3 +278,10 @@ func (r *Rules) ParseDomains(expression string) ([]string, error) {
 	}
 	return fun.Map(types.CanonicalDomain, domains).([]string), nil
 }
+
+// ValidateRule parses the rule expression on a throwaway router, as it is parsed when building the routes of a frontend.
+func ValidateRule(expression string) error {
+	rules := &Rules{route: &serverRoute{route: mux.NewRouter().NewRoute()}}
+	_, err := rules.Parse(expression)
+	return err
+}
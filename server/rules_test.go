@@ -49,6 +49,48 @@ func TestParseTwoRules(t *testing.T) {
 	assert.True(t, routeMatch, "Rule %s don't match.", expression)
 }
 
+func TestValidateRule(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		expression    string
+		expectedError bool
+	}{
+		{
+			desc:       "valid rules",
+			expression: "Host:foo.bar;PathPrefix:/api",
+		},
+		{
+			desc:          "empty rule",
+			expression:    "",
+			expectedError: true,
+		},
+		{
+			desc:          "unknown function",
+			expression:    "Hots:foo.bar",
+			expectedError: true,
+		},
+		{
+			desc:          "invalid regular expression",
+			expression:    "PathPrefix:/{id:[0-9+}",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateRule(test.expression)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestParseDomains(t *testing.T) {
 	rules := &Rules{}
 
@@ -546,6 +546,8 @@ func (s *Server) configureProviders() {
 	}
 	if s.globalConfiguration.File != nil {
 		s.providers = append(s.providers, s.globalConfiguration.File)
+		s.globalConfiguration.File.MetricsRegistry = s.metricsRegistry
+		s.globalConfiguration.File.RuleValidator = ValidateRule
 	}
 	if s.globalConfiguration.Rest != nil {
 		s.providers = append(s.providers, s.globalConfiguration.Rest)
@@ -12,6 +12,10 @@ import (
 
 func (s *Server) configureSignals() {
 	signal.Notify(s.signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1)
+	if s.globalConfiguration.File != nil {
+		// SIGHUP reloads the file configuration instead of stopping Træfik
+		signal.Notify(s.signals, syscall.SIGHUP)
+	}
 }
 
 func (s *Server) listenSignals() {
@@ -30,6 +34,12 @@ func (s *Server) listenSignals() {
 			if err := log.RotateFile(); err != nil {
 				log.Errorf("Error rotating traefik log: %s", err)
 			}
+		case syscall.SIGHUP:
+			log.Infof("Reloading the file configuration: %+v", sig)
+
+			if err := s.globalConfiguration.File.ForceReload(); err != nil {
+				log.Errorf("Error reloading the file configuration: %s", err)
+			}
 		default:
 			log.Infof("I have to go... %+v", sig)
 			reqAcceptGraceTimeOut := time.Duration(s.globalConfiguration.LifeCycle.RequestAcceptGraceTimeout)